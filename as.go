@@ -0,0 +1,23 @@
+package terrors
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+func As[T error](err error) (T, bool) {
+	var target T
+	ok := errors.As(err, &target)
+	return target, ok
+}
+
+// MustAs is like As but panics with a TypeInternal error when err's chain has
+// no T. It is intended for tests.
+func MustAs[T error](err error) T {
+	target, ok := As[T](err)
+	if !ok {
+		panic(Errorf(TypeInternal, "terrors: no %s in error chain", reflect.TypeOf((*T)(nil)).Elem()))
+	}
+	return target
+}
@@ -0,0 +1,80 @@
+package terrors
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+)
+
+type codeError struct {
+	code int
+}
+
+func (e codeError) Error() string {
+	return fmt.Sprintf("code %d", e.code)
+}
+
+func TestAs(t *testing.T) {
+	pathErr := &fs.PathError{Op: "open", Path: "app.yaml", Err: fs.ErrNotExist}
+
+	tests := []struct {
+		name string
+		err  error
+		ok   bool
+	}{
+		{"nil", nil, false},
+		{"direct", pathErr, true},
+		{"wrapped", Wrap(TypeNotExist, pathErr, "load"), true},
+		{"foreign wrapper", fmt.Errorf("load: %w", WithMessage(TypeInternal, pathErr, "read")), true},
+		{"absent", New(TypeInternal, "boom"), false},
+		{"wrong type", Wrap(TypeInternal, os.ErrNotExist, "load"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := As[*fs.PathError](tt.err)
+			if ok != tt.ok {
+				t.Fatalf("As() ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && got != pathErr {
+				t.Errorf("As() = %v, want %v", got, pathErr)
+			}
+			if !ok && got != nil {
+				t.Errorf("As() = %v, want nil", got)
+			}
+		})
+	}
+}
+
+func TestAsValueType(t *testing.T) {
+	got, ok := As[codeError](Wrap(TypeInternal, codeError{code: 7}, "call"))
+	if !ok || got.code != 7 {
+		t.Errorf("As() = %v, %v", got, ok)
+	}
+}
+
+func TestAsInterface(t *testing.T) {
+	got, ok := As[TypedError](fmt.Errorf("serve: %w", New(TypeConflict, "busy")))
+	if !ok || got.Type() != TypeConflict {
+		t.Errorf("As() = %v, %v", got, ok)
+	}
+}
+
+func TestMustAs(t *testing.T) {
+	err := Wrap(TypeInternal, codeError{code: 3}, "call")
+	if got := MustAs[codeError](err); got.code != 3 {
+		t.Errorf("MustAs() = %v", got)
+	}
+
+	defer func() {
+		r := recover()
+		perr, ok := r.(error)
+		if !ok || TypeOf(perr) != TypeInternal || !strings.Contains(perr.Error(), "*fs.PathError") {
+			t.Errorf("MustAs() panicked with %v", r)
+		}
+	}()
+	MustAs[*fs.PathError](err)
+	t.Error("MustAs() did not panic")
+}
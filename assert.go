@@ -0,0 +1,59 @@
+package terrors
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// InvariantLabel is the label set on errors returned by Assert and
+// AssertNotNil, so that they can be told apart in logs and metrics.
+const InvariantLabel = "invariant"
+
+// Assert returns nil when cond holds and otherwise a TypeInternal error
+// "invariant violated: ..." with the stack of the caller.
+func Assert(cond bool, format string, args ...interface{}) error {
+	if cond {
+		return nil
+	}
+	return invariant(fmt.Sprintf(format, args...))
+}
+
+// AssertNotNil is like Assert for v != nil, except that it also fails for
+// an interface holding a nil pointer, map, slice, channel or function.
+func AssertNotNil(v interface{}, name string) error {
+	if !isNil(v) {
+		return nil
+	}
+	return invariant(name + " is nil")
+}
+
+// IsInvariant reports whether err was returned by Assert or AssertNotNil.
+func IsInvariant(err error) bool {
+	return Labels(err)[InvariantLabel] == "true"
+}
+
+func invariant(msg string) error {
+	msg = "invariant violated: " + msg
+	stack := errors.New(msg).(StackTracer).StackTrace()
+
+	f := newFundamental(TypeInternal, msg, stack[2:])
+	if f.labels == nil {
+		f.labels = map[string]string{}
+	}
+	f.labels[InvariantLabel] = "true"
+	return f
+}
+
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func, reflect.Interface, reflect.UnsafePointer:
+		return rv.IsNil()
+	}
+	return false
+}
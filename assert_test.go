@@ -0,0 +1,70 @@
+package terrors
+
+import (
+	"bytes"
+	"testing"
+)
+
+type nilStringer struct{}
+
+func (*nilStringer) String() string { return "" }
+
+func TestAssertNotNil(t *testing.T) {
+	var (
+		ptr   *bytes.Buffer
+		str   *nilStringer
+		iface interface{ String() string } = str
+		m     map[string]int
+		s     []int
+		ch    chan int
+		fn    func()
+	)
+
+	tests := []struct {
+		name string
+		v    interface{}
+		nil  bool
+	}{
+		{"nil", nil, true},
+		{"typed nil pointer", ptr, true},
+		{"typed nil in an interface", iface, true},
+		{"nil map", m, true},
+		{"nil slice", s, true},
+		{"nil chan", ch, true},
+		{"nil func", fn, true},
+		{"pointer", &bytes.Buffer{}, false},
+		{"empty slice", []int{}, false},
+		{"zero int", 0, false},
+		{"empty string", "", false},
+		{"zero struct", struct{}{}, false},
+	}
+	for _, tt := range tests {
+		err := AssertNotNil(tt.v, "v")
+		if (err != nil) != tt.nil {
+			t.Errorf("%s: AssertNotNil() = %v", tt.name, err)
+			continue
+		}
+		if err == nil {
+			continue
+		}
+		if err.Error() != "invariant violated: v is nil" || TypeOf(err) != TypeInternal || !IsInvariant(err) {
+			t.Errorf("%s: AssertNotNil() = %v of type %v", tt.name, err, TypeOf(err))
+		}
+		if function, _, _, _ := Caller(err); function != "github.com/thamaji/terrors.TestAssertNotNil" {
+			t.Errorf("%s: Caller() = %s", tt.name, function)
+		}
+	}
+}
+
+func TestAssert(t *testing.T) {
+	if err := Assert(true, "never"); err != nil {
+		t.Errorf("Assert(true) = %v", err)
+	}
+	err := Assert(false, "n = %d", 3)
+	if err == nil || err.Error() != "invariant violated: n = 3" || !IsInvariant(err) {
+		t.Errorf("Assert(false) = %v", err)
+	}
+	if IsInvariant(New(TypeInternal, "invariant violated: n = 3")) {
+		t.Error("IsInvariant() of a plain error = true")
+	}
+}
@@ -0,0 +1,344 @@
+package terrors
+
+import (
+	"encoding/binary"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync/atomic"
+)
+
+const binaryVersion = 2
+
+const (
+	binaryTyped = 1 << iota
+	binaryMessage
+	binaryJoined
+	binarySecondary
+)
+
+// chainLayer is what the encodings keep of a layer: its type, if any, the
+// message it contributes, for a join the chains of the joined errors, the
+// chains of the errors attached with WithSecondary, and the payload of
+// WithDetails, which only Encode keeps.
+type chainLayer struct {
+	flags     byte
+	t         Type
+	msg       string
+	joined    [][]chainLayer
+	secondary [][]chainLayer
+	details   interface{}
+}
+
+func chainLayers(err error) []chainLayer {
+	var layers []chainLayer
+	for e := err; e != nil; e = unwrapOnce(e) {
+		var l chainLayer
+		if te, ok := e.(TypedError); ok {
+			l.flags |= binaryTyped
+			l.t = te.Type()
+		}
+		msg, split := ownMessage(e)
+		if !split && l.flags&binaryTyped == 0 {
+			// the layers below are not encoded, keep at least their type
+			if t, ok := TypeOk(unwrapOnce(e)); ok {
+				l.flags |= binaryTyped
+				l.t = t
+			}
+		}
+		if _, prefix, _, _ := layerMessage(e); prefix || msg != "" || unwrapOnce(e) == nil || !split {
+			l.flags |= binaryMessage
+			l.msg = msg
+		}
+		if joined, ok := e.(interface{ Unwrap() []error }); ok && unwrapOnce(e) == nil {
+			l.flags |= binaryJoined
+			for _, j := range joined.Unwrap() {
+				if j != nil {
+					l.joined = append(l.joined, chainLayers(j))
+				}
+			}
+		}
+		if w, ok := e.(*withSecondary); ok {
+			l.flags |= binarySecondary
+			for _, s := range w.secondary {
+				l.secondary = append(l.secondary, chainLayers(s))
+			}
+		}
+		if d, ok := e.(*withDetails); ok {
+			l.details = d.details
+		}
+		if l.flags != 0 || l.details != nil {
+			layers = append(layers, l)
+		}
+		if !split {
+			break
+		}
+	}
+	return layers
+}
+
+// buildChain rebuilds a chain from its layers, outermost first.
+func buildChain(layers []chainLayer) error {
+	var err error
+	for i := len(layers) - 1; i >= 0; i-- {
+		l := layers[i]
+		if l.flags&^binarySecondary == 0 && err != nil {
+			if l.details != nil {
+				err = &withDetails{cause: err, details: l.details}
+			}
+			if l.flags&binarySecondary != 0 {
+				w := &withSecondary{cause: err, secondary: make([]error, len(l.secondary))}
+				for i, layers := range l.secondary {
+					w.secondary[i] = buildChain(layers)
+				}
+				err = w
+			}
+			continue
+		}
+		typed := l.flags&binaryTyped != 0
+		switch {
+		case err == nil && l.flags&binaryJoined != 0:
+			j := &joinedError{msg: l.msg, errs: make([]error, len(l.joined))}
+			for i, layers := range l.joined {
+				j.errs[i] = buildChain(layers)
+			}
+			err = j
+			if typed {
+				err = &withStack{t: l.t, cause: err}
+			}
+		case err == nil && typed:
+			err = &fundamental{t: l.t, msg: l.msg}
+		case err == nil:
+			err = stderrors.New(l.msg)
+		case typed && l.flags&binaryMessage != 0:
+			err = &withMessage{t: l.t, cause: err, msg: l.msg}
+		case typed:
+			err = &withStack{t: l.t, cause: err}
+		default:
+			err = &withPlainMessage{cause: err, msg: l.msg}
+		}
+	}
+	return err
+}
+
+// AppendBinary appends a compact encoding of err's chain to b: for every
+// layer the name of its type, if any, the message it contributes, the chains
+// of the errors it joins and of its secondary errors, followed by the labels
+// of the chain. Stacks and details payloads are not encoded, see Encode for
+// the latter. The first byte is a format version checked by DecodeBinary.
+func AppendBinary(b []byte, err error) []byte {
+	b = append(b, binaryVersion)
+	b = appendLayers(b, chainLayers(err))
+
+	labels := Labels(err)
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	b = binary.AppendUvarint(b, uint64(len(keys)))
+	for _, k := range keys {
+		b = appendString(b, k)
+		b = appendString(b, labels[k])
+	}
+	return b
+}
+
+func appendLayers(b []byte, layers []chainLayer) []byte {
+	n := 0
+	for _, l := range layers {
+		if l.flags != 0 {
+			n++
+		}
+	}
+
+	b = binary.AppendUvarint(b, uint64(n))
+	for _, l := range layers {
+		if l.flags == 0 {
+			continue
+		}
+		b = append(b, l.flags)
+		if l.flags&binaryTyped != 0 {
+			b = appendString(b, l.t.String())
+		}
+		if l.flags&binaryMessage != 0 {
+			b = appendString(b, l.msg)
+		}
+		if l.flags&binaryJoined != 0 {
+			b = binary.AppendUvarint(b, uint64(len(l.joined)))
+			for _, j := range l.joined {
+				b = appendLayers(b, j)
+			}
+		}
+		if l.flags&binarySecondary != 0 {
+			b = binary.AppendUvarint(b, uint64(len(l.secondary)))
+			for _, s := range l.secondary {
+				b = appendLayers(b, s)
+			}
+		}
+	}
+	return b
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+// DecodeBinary decodes data produced by AppendBinary. Malformed data, unknown
+// versions and type names that are not registered yield a TypeInvalid error.
+func DecodeBinary(data []byte) (error, error) {
+	if len(data) == 0 {
+		return nil, New(TypeInvalid, "terrors: empty binary data")
+	}
+	if data[0] != binaryVersion {
+		return nil, Errorf(TypeInvalid, "terrors: unsupported binary version %d", data[0])
+	}
+
+	r := &binaryReader{data: data[1:]}
+	layers := r.layers()
+
+	var labels map[string]string
+	for n := r.count(); n > 0 && r.err == nil; n-- {
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		k := r.string()
+		labels[k] = r.string()
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	if len(r.data) != 0 {
+		return nil, New(TypeInvalid, "terrors: trailing binary data")
+	}
+
+	err := buildChain(layers)
+	if len(labels) > 0 {
+		err = &withLabels{cause: err, labels: labels}
+	}
+	return err, nil
+}
+
+// binaryReader consumes the data of DecodeBinary, keeping the first error.
+type binaryReader struct {
+	data []byte
+	err  error
+}
+
+func (r *binaryReader) fail(msg string) {
+	if r.err == nil {
+		r.err = New(TypeInvalid, msg)
+	}
+	r.data = nil
+}
+
+func (r *binaryReader) count() uint64 {
+	n, size := binary.Uvarint(r.data)
+	if size <= 0 || n > uint64(len(r.data)) {
+		r.fail("terrors: malformed binary data")
+		return 0
+	}
+	r.data = r.data[size:]
+	return n
+}
+
+func (r *binaryReader) string() string {
+	n := r.count()
+	if n > uint64(len(r.data)) {
+		r.fail("terrors: truncated binary data")
+		return ""
+	}
+	s := string(r.data[:n])
+	r.data = r.data[n:]
+	return s
+}
+
+func (r *binaryReader) layers() []chainLayer {
+	n := r.count()
+	layers := make([]chainLayer, 0, n)
+	for ; n > 0 && r.err == nil; n-- {
+		if len(r.data) == 0 {
+			r.fail("terrors: truncated binary data")
+			break
+		}
+		l := chainLayer{flags: r.data[0]}
+		r.data = r.data[1:]
+
+		if l.flags&binaryTyped != 0 {
+			name := r.string()
+			t, ok := ParseType(name)
+			if !ok && r.err == nil {
+				r.err = Errorf(TypeInvalid, "terrors: unknown type %q", name)
+			}
+			l.t = t
+		}
+		if l.flags&binaryMessage != 0 {
+			l.msg = r.string()
+		}
+		if l.flags&binaryJoined != 0 {
+			for j := r.count(); j > 0 && r.err == nil; j-- {
+				l.joined = append(l.joined, r.layers())
+			}
+		}
+		if l.flags&binarySecondary != 0 {
+			for j := r.count(); j > 0 && r.err == nil; j-- {
+				l.secondary = append(l.secondary, r.layers())
+			}
+		}
+		layers = append(layers, l)
+	}
+	return layers
+}
+
+// joinedError is a decoded join (Unwrap() []error), keeping the message of
+// the original.
+type joinedError struct {
+	msg  string
+	errs []error
+}
+
+func (j *joinedError) Error() string {
+	return j.msg
+}
+
+func (j *joinedError) Unwrap() []error {
+	return j.errs
+}
+
+// withPlainMessage is an untyped annotation, used for decoded layers that
+// were not created by this package.
+type withPlainMessage struct {
+	cause error
+	msg   string
+	text  atomic.Pointer[string]
+}
+
+func (w *withPlainMessage) Error() string {
+	return cachedMessage(&w.text, w)
+}
+
+func (w *withPlainMessage) Cause() error {
+	return w.cause
+}
+
+func (w *withPlainMessage) Unwrap() error {
+	return w.cause
+}
+
+func (w *withPlainMessage) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			formatVerbose(s, w)
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, w.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", w.Error())
+	}
+}
@@ -0,0 +1,177 @@
+package terrors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	quota, err := RegisterType("test_quota_exceeded")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"new", New(TypeNotExist, "user not found")},
+		{"foreign", io.EOF},
+		{"wrapped foreign", Wrap(TypeInternal, fmt.Errorf("read body: %w", io.EOF), "decode")},
+		{"message only", WithMessage(TypeInvalid, New(TypeNotExist, "missing"), "")},
+		{"registered type", Wrap(quota, New(TypeUnavailable, "429"), "call api")},
+		{"join", Wrap(TypeConflict, stderrors.Join(New(TypeInternal, "a"), nil, New(TypeNotExist, "b")), "save")},
+		{"bare join", stderrors.Join(io.EOF, New(TypeInternal, "b"))},
+		{"join of joins", stderrors.Join(stderrors.Join(io.EOF, New(TypeNotExist, "c")), New(TypeInvalid, "d"))},
+		{"multi %w", fmt.Errorf("both %w and %w", New(TypeTimeout, "slow"), io.EOF)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := DecodeBinary(AppendBinary(nil, tt.err))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if decoded.Error() != tt.err.Error() {
+				t.Errorf("Error() = %q, want %q", decoded.Error(), tt.err.Error())
+			}
+			if TypeOf(decoded) != TypeOf(tt.err) || RootType(decoded) != RootType(tt.err) {
+				t.Errorf("types = %v/%v, want %v/%v", TypeOf(decoded), RootType(decoded), TypeOf(tt.err), RootType(tt.err))
+			}
+			if got, want := typesOf(decoded), typesOf(tt.err); got != want {
+				t.Errorf("types of the tree = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+// typesOf lists the types of every error in err's tree.
+func typesOf(err error) string {
+	var types []Type
+	walk(err, func(e error) bool {
+		if te, ok := e.(TypedError); ok {
+			types = append(types, te.Type())
+		}
+		return true
+	})
+	return fmt.Sprint(types)
+}
+
+func TestBinaryUnregisteredType(t *testing.T) {
+	custom := Type(1000)
+	err := Wrap(custom, New(custom, "over quota"), "call api")
+
+	decoded, derr := DecodeBinary(AppendBinary(nil, err))
+	if derr != nil {
+		t.Fatal(derr)
+	}
+	if decoded.Error() != err.Error() || TypeOf(decoded) != custom || RootType(decoded) != custom {
+		t.Errorf("decoded %q of type %v/%v, want %q of type %v", decoded, TypeOf(decoded), RootType(decoded), err, custom)
+	}
+
+	if decoded, derr := Decode(Encode(err, false)); derr != nil || TypeOf(decoded) != custom {
+		t.Errorf("Decode() = %v (%v), %v", decoded, TypeOf(decoded), derr)
+	}
+
+	for _, name := range []string{"Type(1000)", "Type(-3)"} {
+		if typ, ok := ParseType(name); !ok || typ.String() != name {
+			t.Errorf("ParseType(%q) = %v, %v", name, typ, ok)
+		}
+	}
+	for _, name := range []string{"Type(01000)", "Type(1)", "Type(x)", "Type(1000", "Type()"} {
+		if typ, ok := ParseType(name); ok {
+			t.Errorf("ParseType(%q) = %v, want none", name, typ)
+		}
+	}
+}
+
+func TestBinaryLabels(t *testing.T) {
+	err := Wrap(TypeUnavailable, Assert(false, "queue %s is empty", "jobs"), "dequeue")
+
+	decoded, derr := DecodeBinary(AppendBinary(nil, err))
+	if derr != nil {
+		t.Fatal(derr)
+	}
+	if !IsInvariant(decoded) {
+		t.Error("IsInvariant() = false after decoding")
+	}
+	if fmt.Sprint(Labels(decoded)) != fmt.Sprint(Labels(err)) {
+		t.Errorf("Labels() = %v, want %v", Labels(decoded), Labels(err))
+	}
+}
+
+func TestBinaryPrefix(t *testing.T) {
+	prefix := []byte("key:")
+	b := AppendBinary(prefix, New(TypeInvalid, "bad"))
+	if string(b[:len(prefix)]) != "key:" {
+		t.Fatalf("AppendBinary overwrote the prefix: %q", b)
+	}
+	if decoded, err := DecodeBinary(b[len(prefix):]); err != nil || decoded.Error() != "bad" {
+		t.Errorf("DecodeBinary() = %v, %v", decoded, err)
+	}
+}
+
+func TestDecodeBinaryInvalid(t *testing.T) {
+	valid := AppendBinary(nil, Wrap(TypeInternal, New(TypeNotExist, "missing"), "load"))
+
+	unknownType := []byte{binaryVersion, 1, binaryTyped | binaryMessage}
+	unknownType = appendString(unknownType, "no_such_type")
+	unknownType = appendString(unknownType, "msg")
+	unknownType = append(unknownType, 0)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"unknown version", append([]byte{binaryVersion + 1}, valid[1:]...)},
+		{"version 1", append([]byte{1}, valid[1:]...)},
+		{"truncated", valid[:len(valid)-3]},
+		{"trailing", append(append([]byte{}, valid...), 0)},
+		{"unknown type", unknownType},
+		{"huge count", []byte{binaryVersion, 0xff, 0xff, 0x03}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := DecodeBinary(tt.data)
+			if decoded != nil || TypeOf(err) != TypeInvalid {
+				t.Errorf("DecodeBinary() = %v, %v; want a TypeInvalid error", decoded, err)
+			}
+		})
+	}
+}
+
+// secondaryErr is an error with two secondary errors, one of them a chain.
+func secondaryErr() error {
+	err := Wrap(TypeInternal, New(TypeConflict, "insert"), "save")
+	err = WithSecondary(err, Wrap(TypeUnavailable, io.ErrClosedPipe, "rollback"))
+	err = WithSecondary(err, io.EOF)
+	return Wrap(TypeInternal, err, "handle")
+}
+
+// secondaryOf describes the secondary errors of err.
+func secondaryOf(err error) string {
+	var s []string
+	for _, e := range Secondary(err) {
+		s = append(s, fmt.Sprintf("%v: %v %s", TypeOf(e), e, typesOf(e)))
+	}
+	return fmt.Sprint(s)
+}
+
+func TestBinarySecondary(t *testing.T) {
+	err := secondaryErr()
+
+	decoded, derr := DecodeBinary(AppendBinary(nil, err))
+	if derr != nil {
+		t.Fatal(derr)
+	}
+	if decoded.Error() != err.Error() || typesOf(decoded) != typesOf(err) {
+		t.Errorf("decoded %q %s, want %q %s", decoded, typesOf(decoded), err, typesOf(err))
+	}
+	if got, want := secondaryOf(decoded), secondaryOf(err); got != want {
+		t.Errorf("Secondary() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,84 @@
+package terrors
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrInternal is the sentinel of the default boundary rule: errors converted
+// for a type missing from a policy without Default match it with errors.Is.
+var ErrInternal error = &boundaryError{t: TypeInternal, msg: "internal error"}
+
+// BoundaryRule is what crosses a boundary for one internal type: an error
+// of type Type whose message is Message, matching Sentinel with errors.Is
+// when it is set. An empty Message defaults to the message of Sentinel, or
+// else to the name of Type.
+type BoundaryRule struct {
+	Type     Type
+	Sentinel error
+	Message  string
+}
+
+// BoundaryPolicy maps the types of internal errors to what a public API
+// exposes, see ConvertBoundary. Types missing from Rules use Default, and a
+// zero Default is a TypeInternal error matching ErrInternal.
+type BoundaryPolicy struct {
+	Rules   map[Type]BoundaryRule
+	Default BoundaryRule
+}
+
+// ConvertBoundary replaces err by a new error built from the rule of policy
+// for TypeOf(err). Nothing of err survives: not its message, its stacks, its
+// labels nor the errors it wraps. A nil error yields nil.
+func ConvertBoundary(err error, policy BoundaryPolicy) error {
+	if err == nil {
+		return nil
+	}
+
+	rule, ok := policy.Rules[TypeOf(err)]
+	if !ok {
+		rule = policy.Default
+		if rule.Type == TypeUnknown && rule.Sentinel == nil && rule.Message == "" {
+			rule = BoundaryRule{Type: TypeInternal, Sentinel: ErrInternal}
+		}
+	}
+
+	msg := rule.Message
+	switch {
+	case msg != "":
+	case rule.Sentinel != nil:
+		msg = rule.Sentinel.Error()
+	default:
+		msg = strings.ReplaceAll(rule.Type.String(), "_", " ")
+	}
+
+	return &boundaryError{t: rule.Type, msg: msg, sentinel: rule.Sentinel}
+}
+
+type boundaryError struct {
+	t        Type
+	msg      string
+	sentinel error
+}
+
+func (e *boundaryError) Type() Type {
+	return e.t
+}
+
+func (e *boundaryError) Error() string {
+	return e.msg
+}
+
+func (e *boundaryError) Unwrap() error {
+	return e.sentinel
+}
+
+func (e *boundaryError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v', 's':
+		io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	}
+}
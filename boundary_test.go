@@ -0,0 +1,88 @@
+package terrors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"runtime/pprof"
+	"strings"
+	"testing"
+)
+
+var errPublicNotFound = stderrors.New("not found")
+
+func TestConvertBoundary(t *testing.T) {
+	policy := BoundaryPolicy{Rules: map[Type]BoundaryRule{
+		TypeNotExist: {Type: TypeNotExist, Sentinel: errPublicNotFound},
+		TypeInvalid:  {Type: TypeInvalid, Message: "invalid request"},
+		TypeTimeout:  {Type: TypeUnavailable},
+	}}
+
+	tests := []struct {
+		name     string
+		err      error
+		t        Type
+		msg      string
+		sentinel error
+	}{
+		{"sentinel", Wrap(TypeNotExist, stderrors.New("secret row 42"), "lookup"), TypeNotExist, "not found", errPublicNotFound},
+		{"message", New(TypeInvalid, "column ssn is null"), TypeInvalid, "invalid request", nil},
+		{"type name", New(TypeTimeout, "dial 10.0.0.1"), TypeUnavailable, "unavailable", nil},
+		{"unmapped", New(TypePermission, "user 7 lacks role admin"), TypeInternal, "internal error", ErrInternal},
+		{"untyped", stderrors.New("password=hunter2"), TypeInternal, "internal error", ErrInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ConvertBoundary(tt.err, policy)
+			if TypeOf(got) != tt.t || got.Error() != tt.msg {
+				t.Errorf("ConvertBoundary() = %v: %q, want %v: %q", TypeOf(got), got, tt.t, tt.msg)
+			}
+			if tt.sentinel != nil && !stderrors.Is(got, tt.sentinel) {
+				t.Errorf("errors.Is(%q, %q) = false", got, tt.sentinel)
+			}
+			if tt.sentinel != ErrInternal && stderrors.Is(got, ErrInternal) {
+				t.Errorf("a mapped type matches ErrInternal")
+			}
+		})
+	}
+
+	if ConvertBoundary(nil, policy) != nil {
+		t.Error("ConvertBoundary(nil) != nil")
+	}
+}
+
+func TestConvertBoundaryLeaks(t *testing.T) {
+	const secret = "tenant=acme secret"
+
+	CaptureGoroutineLabels(true)
+	defer CaptureGoroutineLabels(false)
+
+	var err error
+	pprof.Do(context.Background(), pprof.Labels("owner", secret), func(ctx context.Context) {
+		inner := WithDetails(New(TypeConflict, secret), secret)
+		err = WithLabels(ctx, WithSecondary(Wrap(TypeUnauthorized, inner, secret), New(TypeInternal, secret)))
+	})
+
+	for _, policy := range []BoundaryPolicy{{}, {Rules: map[Type]BoundaryRule{TypeInvalid: {Type: TypeInvalid}}}} {
+		got := ConvertBoundary(err, policy)
+		if !stderrors.Is(got, ErrInternal) {
+			t.Errorf("errors.Is(ErrInternal) = false for %q", got)
+		}
+
+		out := strings.Join([]string{
+			got.Error(), fmt.Sprintf("%+v", got), fmt.Sprintf("%#v", got), Tree(got), Render(got, true),
+			fmt.Sprint(Labels(got)), fmt.Sprint(Secondary(got)), fmt.Sprint(Encode(got, true)),
+			string(AppendBinary(nil, got)),
+		}, "\n")
+		if strings.Contains(out, "secret") || strings.Contains(out, "acme") {
+			t.Errorf("internal text survived the boundary:\n%s", out)
+		}
+		if _, ok := StackTrace(got); ok {
+			t.Errorf("the converted error has a stack")
+		}
+		if _, ok := Details[string](got); ok {
+			t.Errorf("the converted error has details")
+		}
+	}
+}
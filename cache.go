@@ -0,0 +1,57 @@
+package terrors
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// stackCacheSize bounds the number of call paths remembered by NewCached.
+// Each entry costs its pcs twice, about 512 bytes.
+const stackCacheSize = 1024
+
+// cacheKey holds the pcs of a call path, as many as a stack keeps.
+type cacheKey [32]uintptr
+
+var (
+	// stackCache maps the pcs of a call path to the stack built from them.
+	stackCache    sync.Map
+	stackCacheLen atomic.Int64
+)
+
+// NewCached is like New but reuses the stack built by a previous call
+// through the same call path, which saves allocating and filling it on hot
+// paths; the pcs are still walked, so that the stack is always exact. At
+// most 1024 call paths are remembered, the others get a stack of their own
+// like New. Cached stacks are shared and never modified.
+func NewCached(t Type, msg string) error {
+	var key cacheKey
+	n := runtime.Callers(2, key[:])
+	return newFundamental(t, msg, cachedStack(&key, n))
+}
+
+// cachedStack returns the stack cached for key, or builds it from the n pcs
+// of key on a miss.
+func cachedStack(key *cacheKey, n int) errors.StackTrace {
+	if n == 0 {
+		return nil
+	}
+	if v, ok := stackCache.Load(*key); ok {
+		return v.(errors.StackTrace)
+	}
+
+	st := make(errors.StackTrace, n)
+	for i, pc := range key[:n] {
+		st[i] = errors.Frame(pc)
+	}
+	if stackCacheLen.Load() >= stackCacheSize {
+		return st
+	}
+	v, loaded := stackCache.LoadOrStore(*key, st)
+	if !loaded {
+		stackCacheLen.Add(1)
+	}
+	return v.(errors.StackTrace)
+}
@@ -0,0 +1,147 @@
+package terrors
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func cachedSiteA() error {
+	return NewCached(TypeInvalid, "a")
+}
+
+func cachedSiteB() error {
+	return NewCached(TypeInvalid, "b")
+}
+
+func TestNewCachedCallSites(t *testing.T) {
+	var a []error
+	for i := 0; i < 2; i++ {
+		a = append(a, cachedSiteA())
+	}
+	b := cachedSiteB()
+
+	stA1, _ := StackTrace(a[0])
+	stA2, _ := StackTrace(a[1])
+	stB, _ := StackTrace(b)
+	if &stA1[0] != &stA2[0] {
+		t.Error("calls from the same site do not share their stack")
+	}
+	if &stA1[0] == &stB[0] {
+		t.Error("different call sites share a stack")
+	}
+
+	function, file, line, _ := Caller(b)
+	if function != "github.com/thamaji/terrors.cachedSiteB" || !strings.HasSuffix(file, "cache_test.go") || line != 16 {
+		t.Errorf("Caller() = %s %s:%d, want cachedSiteB", function, file, line)
+	}
+	if fn := fmt.Sprintf("%n", stA1[0]); fn != "cachedSiteA" {
+		t.Errorf("stack of site A starts at %s", fn)
+	}
+}
+
+func TestNewCachedMatchesNew(t *testing.T) {
+	cached := func() error { return NewCached(TypeInvalid, "x") }
+	var errs []error
+	for i := 0; i < 3; i++ {
+		errs = append(errs, cached())
+	}
+	fresh := func() error { return New(TypeInvalid, "x") }()
+
+	for _, err := range errs {
+		got := StackLines(err, 0)
+		want := StackLines(fresh, 0)
+		if len(got) != len(want) || got[len(got)-1] != want[len(want)-1] {
+			t.Errorf("cached stack %q, want the shape of %q", got, want)
+		}
+		if !strings.HasPrefix(got[0], "github.com/thamaji/terrors.TestNewCachedMatchesNew.func1 ") {
+			t.Errorf("cached stack starts at %q", got[0])
+		}
+	}
+	if ID(errs[0]) == ID(errs[1]) || errs[0].Error() != "x" || TypeOf(errs[0]) != TypeInvalid {
+		t.Error("cached errors are not distinct errors")
+	}
+}
+
+func TestNewCachedCallers(t *testing.T) {
+	// the same site reached through different callers does not share
+	viaOne := func() error { return cachedSiteA() }
+	viaTwo := func() error { return cachedSiteA() }
+
+	st1, _ := StackTrace(viaOne())
+	st2, _ := StackTrace(viaTwo())
+	if &st1[0] == &st2[0] || fmt.Sprintf("%n", st1[1]) == fmt.Sprintf("%n", st2[1]) {
+		t.Errorf("callers share a stack: %n and %n", st1[1], st2[1])
+	}
+}
+
+func TestNewCachedOuterPaths(t *testing.T) {
+	// the paths only differ beyond the innermost few frames
+	viaOne := func() error { return deep(5, cachedSiteA) }
+	viaTwo := func() error { return deep(5, cachedSiteA) }
+
+	for i := 0; i < 2; i++ {
+		for _, tt := range []struct {
+			via  func() error
+			name string
+		}{{viaOne, "TestNewCachedOuterPaths.func1"}, {viaTwo, "TestNewCachedOuterPaths.func2"}} {
+			lines := StackLines(tt.via(), 0)
+			if len(lines) < 8 || !strings.Contains(lines[7], tt.name+" ") {
+				t.Fatalf("stack through %s = %q", tt.name, lines)
+			}
+		}
+	}
+}
+
+func TestNewCachedBound(t *testing.T) {
+	stackCacheLen.Add(stackCacheSize)
+	defer stackCacheLen.Add(-stackCacheSize)
+
+	// a new path past the bound is not remembered but still exact
+	site := func() error { return NewCached(TypeInvalid, "x") }
+	var stacks []string
+	for i := 0; i < 2; i++ {
+		st, _ := StackTrace(site())
+		if _, ok := stackCache.Load(cacheKeyOf(st)); ok {
+			t.Fatal("a path past the bound is cached")
+		}
+		stacks = append(stacks, fmt.Sprintf("%n", st[0]))
+	}
+	if stacks[0] != "TestNewCachedBound.func1" || stacks[1] != stacks[0] {
+		t.Errorf("stacks start at %q", stacks)
+	}
+}
+
+func cacheKeyOf(st []errors.Frame) cacheKey {
+	var key cacheKey
+	for i, f := range st {
+		key[i] = uintptr(f)
+	}
+	return key
+}
+
+// deep calls fn under n more frames, for benchmarks of deep stacks.
+func deep(n int, fn func() error) error {
+	if n == 0 {
+		return fn()
+	}
+	return deep(n-1, fn)
+}
+
+func BenchmarkNew(b *testing.B) {
+	fn := func() error { return New(TypeInvalid, "x") }
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = deep(20, fn)
+	}
+}
+
+func BenchmarkNewCached(b *testing.B) {
+	fn := func() error { return NewCached(TypeInvalid, "x") }
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = deep(20, fn)
+	}
+}
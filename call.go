@@ -0,0 +1,38 @@
+package terrors
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// Call runs fn and classifies its failure, annotated with op (see WithOp):
+//
+//   - a panic becomes a TypeInternal error with the stack of the panic
+//   - an untyped context.Canceled or context.DeadlineExceeded becomes a
+//     TypeCanceled or TypeTimeout error
+//   - any other untyped error gets type t and the stack of the caller
+//   - a typed error is returned as is
+//
+// A nil error stays nil.
+func Call(ctx context.Context, t Type, op string, fn func(context.Context) error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = WithOp(FromPanic(p), op)
+		}
+	}()
+
+	err = fn(ctx)
+	if err == nil {
+		return nil
+	}
+	if _, typed := TypeOk(err); typed {
+		return WithOp(err, op)
+	}
+
+	stack := errors.New("").(StackTracer).StackTrace()[1:]
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return WithOp(contextError(err, stack), op)
+	}
+	return WithOp(newWithStack(t, err, stack), op)
+}
@@ -0,0 +1,75 @@
+package terrors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCall(t *testing.T) {
+	typed := New(TypeConflict, "busy")
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name  string
+		ctx   context.Context
+		fn    func(context.Context) error
+		t     Type
+		msg   string
+		cause error
+	}{
+		{"untyped", context.Background(), func(context.Context) error { return io.ErrUnexpectedEOF }, TypeUnavailable, "unexpected EOF", io.ErrUnexpectedEOF},
+		{"typed", context.Background(), func(context.Context) error { return typed }, TypeConflict, "busy", typed},
+		{"canceled", canceled, func(ctx context.Context) error { return ctx.Err() }, TypeCanceled, "context canceled", context.Canceled},
+		{"deadline", context.Background(), func(context.Context) error { return fmt.Errorf("query: %w", context.DeadlineExceeded) }, TypeTimeout, "query: context deadline exceeded", context.DeadlineExceeded},
+		{"panic", context.Background(), func(context.Context) error { panic("boom") }, TypeInternal, "panic: boom", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Call(tt.ctx, TypeUnavailable, "fetch", tt.fn)
+			if err == nil {
+				t.Fatal("Call() = nil")
+			}
+			if TypeOf(err) != tt.t || err.Error() != tt.msg {
+				t.Errorf("Call() = %v: %q, want %v: %q", TypeOf(err), err, tt.t, tt.msg)
+			}
+			if ops := Ops(err); len(ops) != 1 || ops[0] != "fetch" {
+				t.Errorf("Ops() = %q, want [fetch]", ops)
+			}
+			if tt.cause != nil && !stderrors.Is(err, tt.cause) {
+				t.Errorf("errors.Is(%q, %q) = false", err, tt.cause)
+			}
+			if _, ok := StackTrace(err); !ok {
+				t.Errorf("%q has no stack", err)
+			}
+		})
+	}
+}
+
+func TestCallStack(t *testing.T) {
+	err := Call(context.Background(), TypeInternal, "op", func(context.Context) error { return io.EOF })
+	if function, _, _, _ := Caller(err); function != "github.com/thamaji/terrors.TestCallStack" {
+		t.Errorf("untyped error: Caller() = %s, want the caller of Call", function)
+	}
+
+	err = Call(context.Background(), TypeInternal, "op", func(context.Context) error {
+		panic(io.EOF)
+	})
+	if function, _, _, _ := Caller(err); !strings.HasPrefix(function, "github.com/thamaji/terrors.TestCallStack.func") {
+		t.Errorf("panic: Caller() = %s, want the panicking function", function)
+	}
+	if !stderrors.Is(err, io.EOF) || err.Error() != "panic: EOF" {
+		t.Errorf("a panic with an error: Call() = %q", err)
+	}
+}
+
+func TestCallNil(t *testing.T) {
+	if err := Call(context.Background(), TypeInternal, "op", func(context.Context) error { return nil }); err != nil {
+		t.Errorf("Call() = %v, want nil", err)
+	}
+}
@@ -0,0 +1,38 @@
+package terrors
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// CatalogueEntry describes a type for documentation tooling.
+type CatalogueEntry struct {
+	Type       Type   `json:"type"`
+	TypeName   string `json:"type_name"`
+	HTTPStatus int    `json:"http_status"`
+	ExitCode   int    `json:"exit_code"`
+}
+
+// Catalogue returns an entry for every type of ListTypes, in the same order.
+// The statuses and exit codes are read at call time, so they reflect
+// SetHTTPStatus and SetExitCode.
+func Catalogue() []CatalogueEntry {
+	types := ListTypes()
+	entries := make([]CatalogueEntry, 0, len(types))
+	for _, t := range types {
+		entries = append(entries, CatalogueEntry{
+			Type:       t,
+			TypeName:   t.String(),
+			HTTPStatus: HTTPStatusOf(t),
+			ExitCode:   typeExitCode(t),
+		})
+	}
+	return entries
+}
+
+// WriteCatalogueJSON writes Catalogue to w as an indented JSON array.
+func WriteCatalogueJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(Catalogue())
+}
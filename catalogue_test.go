@@ -0,0 +1,90 @@
+package terrors
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+// builtinCatalogue returns the part of WriteCatalogueJSON describing the
+// types of this package: the registered types depend on the tests run.
+func builtinCatalogue(t *testing.T) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := WriteCatalogueJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var entries []json.RawMessage
+	if err := json.Unmarshal(buf.Bytes(), &entries); err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	for _, e := range entries[:TypeUnavailable+1] {
+		if err := json.Compact(&b, e); err != nil {
+			t.Fatal(err)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func TestCatalogue(t *testing.T) {
+	golden(t, "catalogue", builtinCatalogue(t))
+}
+
+func TestCatalogueRegistered(t *testing.T) {
+	quota, err := RegisterType("test_quota_exceeded")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries := Catalogue()
+	types := ListTypes()
+	if len(entries) != len(types) {
+		t.Fatalf("%d entries for %d types", len(entries), len(types))
+	}
+	n := 0
+	for i, e := range entries {
+		if e.Type != types[i] || e.TypeName != types[i].String() {
+			t.Errorf("entry %d = %+v, want type %v", i, e, types[i])
+		}
+		if e.Type == quota {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Errorf("the registered type has %d entries, want 1", n)
+	}
+}
+
+func TestCatalogueOverrides(t *testing.T) {
+	resetHTTPStatus(t, TypeConflict)
+	exitMu.Lock()
+	code, ok := exitCodes[TypeConflict]
+	exitMu.Unlock()
+	t.Cleanup(func() {
+		exitMu.Lock()
+		if ok {
+			exitCodes[TypeConflict] = code
+		} else {
+			delete(exitCodes, TypeConflict)
+		}
+		exitMu.Unlock()
+	})
+
+	before := Catalogue()[TypeConflict]
+	if err := SetHTTPStatus(TypeConflict, 423); err != nil {
+		t.Fatal(err)
+	}
+	SetExitCode(TypeConflict, 70)
+
+	after := Catalogue()[TypeConflict]
+	if after.HTTPStatus != 423 || after.ExitCode != 70 {
+		t.Errorf("after the overrides: %+v", after)
+	}
+	if before.HTTPStatus == 423 || before.ExitCode == 70 {
+		t.Errorf("before the overrides: %+v", before)
+	}
+}
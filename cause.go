@@ -0,0 +1,119 @@
+package terrors
+
+import (
+	"fmt"
+	"io"
+)
+
+// WithCause returns a copy of err whose chain continues into cause, keeping
+// err's message, type, labels and stacks, so that errors.Is and errors.As
+// find cause and what it wraps. The cause is grafted below the innermost
+// layer created by this package: whatever err wrapped there is replaced,
+// though its message and type are kept. Grafting onto an err that was not
+// created by this package keeps only its message and, for a TypedError, its
+// type. A nil cause returns err unchanged.
+func WithCause(err error, cause error) error {
+	if err == nil || cause == nil {
+		return err
+	}
+	if !owned(err) {
+		return replaced(err, cause)
+	}
+
+	c := copyChain(err)
+	var parent error
+	e := c
+	for {
+		next := unwrapOnce(e)
+		if next == nil || !owned(next) {
+			break
+		}
+		parent, e = e, next
+	}
+
+	switch l := e.(type) {
+	case *withCause:
+		l.cause = cause
+		return c
+	case *fundamental:
+		graft := &withStack{t: l.t, cause: &withCause{cause: cause, msg: l.msg}, stack: l.stack, labels: l.labels, id: l.id}
+		if parent == nil {
+			return graft
+		}
+		setCause(parent, graft)
+		return c
+	}
+
+	setCause(e, replaced(unwrapOnce(e), cause))
+	return c
+}
+
+// replaced stands for the foreign err replaced by cause: its message, over a
+// layer of its type when it is a TypedError.
+func replaced(err error, cause error) error {
+	w := &withCause{cause: cause, msg: err.Error()}
+	if te, ok := err.(TypedError); ok {
+		return &withStack{t: te.Type(), cause: w}
+	}
+	return w
+}
+
+// setCause replaces the cause of a layer copied by copyChain.
+func setCause(err error, cause error) {
+	switch e := err.(type) {
+	case *withStack:
+		e.cause = cause
+	case *withMessage:
+		e.cause = cause
+	case *wrapped:
+		e.cause = cause
+	case *withLabels:
+		e.cause = cause
+	case *withDetails:
+		e.cause = cause
+	case *withHandled:
+		e.cause = cause
+	case *withSecondary:
+		e.cause = cause
+	case *withOp:
+		e.cause = cause
+	case *withPlainMessage:
+		e.cause = cause
+	case *withCause:
+		e.cause = cause
+	}
+}
+
+// withCause ends the message of a chain with msg, while the chain itself
+// continues into cause.
+type withCause struct {
+	cause error
+	msg   string
+}
+
+func (w *withCause) Error() string {
+	return w.msg
+}
+
+func (w *withCause) Cause() error {
+	return w.cause
+}
+
+func (w *withCause) Unwrap() error {
+	return w.cause
+}
+
+func (w *withCause) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			formatVerbose(s, w)
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, w.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", w.Error())
+	}
+}
@@ -0,0 +1,57 @@
+package terrors
+
+import (
+	stderrors "errors"
+	"io"
+	"testing"
+)
+
+// foreignTyped is a TypedError not created by this package.
+type foreignTyped struct{}
+
+func (foreignTyped) Error() string { return "quota exceeded" }
+func (foreignTyped) Type() Type    { return TypeUnavailable }
+
+func TestWithCause(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		msg  string
+		t    Type
+		root Type
+	}{
+		{"new", New(TypeNotExist, "missing"), "missing", TypeNotExist, TypeNotExist},
+		{"wrapped", Wrap(TypeInternal, New(TypeNotExist, "missing"), "load"), "load: missing", TypeInternal, TypeNotExist},
+		{"wrapped foreign", Wrap(TypeInternal, io.ErrUnexpectedEOF, "read"), "read: unexpected EOF", TypeInternal, TypeInternal},
+		{"foreign", io.ErrUnexpectedEOF, "unexpected EOF", DefaultType(), DefaultType()},
+		{"foreign typed", foreignTyped{}, "quota exceeded", TypeUnavailable, TypeUnavailable},
+		{"wrapped foreign typed", Wrap(TypeInternal, foreignTyped{}, "call"), "call: quota exceeded", TypeInternal, TypeUnavailable},
+		{"labeled foreign typed", WithOp(foreignTyped{}, "call"), "quota exceeded", TypeUnavailable, TypeUnavailable},
+	}
+
+	cause := stderrors.New("connection reset")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WithCause(tt.err, cause)
+			if got.Error() != tt.msg {
+				t.Errorf("Error() = %q, want %q", got.Error(), tt.msg)
+			}
+			if TypeOf(got) != tt.t || RootType(got) != tt.root {
+				t.Errorf("types = %v/%v, want %v/%v", TypeOf(got), RootType(got), tt.t, tt.root)
+			}
+			if !stderrors.Is(got, cause) || Cause(got) != cause {
+				t.Errorf("the chain of %q does not end with the cause", got)
+			}
+		})
+	}
+}
+
+func TestWithCauseNil(t *testing.T) {
+	err := New(TypeInternal, "x")
+	if got := WithCause(err, nil); got != err {
+		t.Errorf("WithCause(err, nil) = %v", got)
+	}
+	if got := WithCause(nil, err); got != nil {
+		t.Errorf("WithCause(nil, err) = %v", got)
+	}
+}
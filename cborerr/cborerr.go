@@ -0,0 +1,53 @@
+// Package cborerr encodes terrors chains as CBOR, see terrors.Encode.
+package cborerr
+
+import (
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/thamaji/terrors"
+)
+
+var decMode, _ = cbor.DecOptions{
+	DefaultMapType: reflect.TypeOf(map[string]interface{}(nil)),
+}.DecMode()
+
+// Marshal encodes err's chain, with its innermost stack when stack is set.
+func Marshal(err error, stack bool) ([]byte, error) {
+	return cbor.Marshal(terrors.Encode(err, stack))
+}
+
+// Unmarshal decodes data produced by Marshal. Malformed data yields a
+// TypeInvalid error.
+func Unmarshal(data []byte) (error, error) {
+	var m map[string]interface{}
+	if err := decMode.Unmarshal(data, &m); err != nil {
+		return nil, terrors.Wrap(terrors.TypeInvalid, err, "cborerr: unmarshal")
+	}
+	return terrors.Decode(m)
+}
+
+// Error embeds an error in CBOR payloads:
+//
+//	type Event struct {
+//		Name string
+//		Err  cborerr.Error
+//	}
+//
+// Stacks are not encoded.
+type Error struct {
+	Err error
+}
+
+func (e Error) MarshalCBOR() ([]byte, error) {
+	return Marshal(e.Err, false)
+}
+
+func (e *Error) UnmarshalCBOR(data []byte) error {
+	err, decodeErr := Unmarshal(data)
+	if decodeErr != nil {
+		return decodeErr
+	}
+	e.Err = err
+	return nil
+}
@@ -0,0 +1,104 @@
+package cborerr
+
+import (
+	"context"
+	"errors"
+	"io"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/thamaji/terrors"
+)
+
+type retryInfo struct {
+	Seconds int
+}
+
+func TestRoundTrip(t *testing.T) {
+	var labeled error
+	pprof.Do(context.Background(), pprof.Labels("tenant", "acme"), func(ctx context.Context) {
+		labeled = terrors.WithLabels(ctx, terrors.Wrap(terrors.TypeInternal, io.EOF, "read"))
+	})
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"new", terrors.New(terrors.TypeNotExist, "user not found")},
+		{"foreign", io.EOF},
+		{"labels", labeled},
+		{"join", terrors.Wrap(terrors.TypeConflict, errors.Join(terrors.New(terrors.TypeInternal, "a"), io.EOF), "save")},
+		{"secondary", terrors.WithSecondary(terrors.New(terrors.TypeInternal, "insert"), terrors.New(terrors.TypeUnavailable, "rollback"))},
+		{"details", terrors.WithDetails(terrors.New(terrors.TypeUnavailable, "throttled"), retryInfo{Seconds: 5})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Marshal(tt.err, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Unmarshal(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got.Error() != tt.err.Error() {
+				t.Errorf("Error() = %q, want %q", got.Error(), tt.err.Error())
+			}
+			if terrors.TypeOf(got) != terrors.TypeOf(tt.err) || terrors.RootType(got) != terrors.RootType(tt.err) {
+				t.Errorf("types = %v/%v, want %v/%v", terrors.TypeOf(got), terrors.RootType(got), terrors.TypeOf(tt.err), terrors.RootType(tt.err))
+			}
+			if g, w := terrors.Labels(got)["tenant"], terrors.Labels(tt.err)["tenant"]; g != w {
+				t.Errorf("tenant label = %q, want %q", g, w)
+			}
+			if g, w := len(terrors.Secondary(got)), len(terrors.Secondary(tt.err)); g != w {
+				t.Errorf("%d secondary errors, want %d", g, w)
+			}
+		})
+	}
+}
+
+func TestRoundTripDetails(t *testing.T) {
+	data, err := Marshal(terrors.WithDetails(terrors.New(terrors.TypeUnavailable, "throttled"), retryInfo{Seconds: 5}), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	details, ok := terrors.Details[map[string]interface{}](got)
+	if !ok || details["Seconds"] != uint64(5) {
+		t.Errorf("Details() = %#v, %v", details, ok)
+	}
+}
+
+func TestErrorField(t *testing.T) {
+	type event struct {
+		Name string
+		Err  Error
+	}
+
+	data, err := cbor.Marshal(event{Name: "sync", Err: Error{Err: terrors.Wrap(terrors.TypeTimeout, io.EOF, "fetch")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got event
+	if err := cbor.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "sync" || got.Err.Err.Error() != "fetch: EOF" || terrors.TypeOf(got.Err.Err) != terrors.TypeTimeout {
+		t.Errorf("decoded %+v", got)
+	}
+}
+
+func TestUnmarshalMalformed(t *testing.T) {
+	for _, data := range [][]byte{{0xff}, {0xa1, 0x64, 't', 'y', 'p', 'e', 0x01}} {
+		if _, err := Unmarshal(data); terrors.TypeOf(err) != terrors.TypeInvalid {
+			t.Errorf("Unmarshal(%x) = %v, want TypeInvalid", data, err)
+		}
+	}
+}
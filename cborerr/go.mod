@@ -0,0 +1,15 @@
+module github.com/thamaji/terrors/cborerr
+
+go 1.26.0
+
+require (
+	github.com/fxamacker/cbor/v2 v2.9.4
+	github.com/thamaji/terrors v0.0.0
+)
+
+require (
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+)
+
+replace github.com/thamaji/terrors => ../
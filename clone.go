@@ -0,0 +1,139 @@
+package terrors
+
+// CloneWithMessage returns a copy of err whose outermost message is msg,
+// keeping its type, stack and the rest of its chain. Layers created by this
+// package are copied; the chain below the first foreign error is shared.
+// err is returned unchanged when no copied layer carries a message.
+func CloneWithMessage(err error, msg string) error {
+	c := copyChain(err)
+	for e := c; e != nil; e = unwrapOnce(e) {
+		switch l := e.(type) {
+		case *fundamental:
+			l.msg = msg
+			return c
+		case *withMessage:
+			l.msg = msg
+			return c
+		case *wrapped:
+			l.msg = msg
+			return c
+		case *withPlainMessage:
+			l.msg = msg
+			return c
+		case *withCause:
+			l.msg = msg
+			return c
+		}
+		if !owned(e) {
+			break
+		}
+	}
+	return err
+}
+
+// CloneWithType is like CloneWithMessage but replaces the type of the
+// outermost typed layer.
+func CloneWithType(err error, t Type) error {
+	c := copyChain(err)
+	for e := c; e != nil; e = unwrapOnce(e) {
+		switch l := e.(type) {
+		case *fundamental:
+			l.t = t
+			return c
+		case *withStack:
+			l.t = t
+			return c
+		case *withMessage:
+			l.t = t
+			return c
+		case *wrapped:
+			l.t = t
+			return c
+		}
+		if !owned(e) {
+			break
+		}
+	}
+	return err
+}
+
+// Copy returns a deep copy of err's chain: every layer created by this
+// package is copied along with its labels and secondary errors, so that
+// annotating the copy never affects err. Stacks are immutable and shared, as
+// are details values and foreign errors with everything they wrap.
+func Copy(err error) error {
+	return copyChain(err)
+}
+
+func owned(err error) bool {
+	switch err.(type) {
+	case *fundamental, *withStack, *withMessage, *wrapped, *withLabels, *withDetails, *withHandled, *withSecondary, *withOp, *withPlainMessage, *withCause:
+		return true
+	}
+	return false
+}
+
+// copyChain copies every layer of err's chain created by this package, down
+// to the first foreign error which is shared. Stacks are immutable and shared.
+// Layers caching their message are built anew rather than copied, so that
+// the copy starts without a cached message.
+func copyChain(err error) error {
+	switch e := err.(type) {
+	case *fundamental:
+		c := *e
+		c.labels = copyLabels(e.labels)
+		return &c
+	case *withStack:
+		c := *e
+		c.labels = copyLabels(e.labels)
+		c.cause = copyChain(e.cause)
+		return &c
+	case *withMessage:
+		return &withMessage{t: e.t, cause: copyChain(e.cause), msg: e.msg}
+	case *wrapped:
+		return &wrapped{t: e.t, cause: copyChain(e.cause), msg: e.msg, stack: e.stack, labels: copyLabels(e.labels), id: e.id}
+	case *withLabels:
+		c := *e
+		c.labels = copyLabels(e.labels)
+		c.cause = copyChain(e.cause)
+		return &c
+	case *withDetails:
+		c := *e
+		c.cause = copyChain(e.cause)
+		return &c
+	case *withHandled:
+		c := *e
+		c.cause = copyChain(e.cause)
+		return &c
+	case *withSecondary:
+		c := *e
+		c.cause = copyChain(e.cause)
+		c.secondary = make([]error, len(e.secondary))
+		for i, s := range e.secondary {
+			c.secondary[i] = copyChain(s)
+		}
+		return &c
+	case *withOp:
+		c := *e
+		c.cause = copyChain(e.cause)
+		return &c
+	case *withPlainMessage:
+		return &withPlainMessage{cause: copyChain(e.cause), msg: e.msg}
+	case *withCause:
+		c := *e
+		c.cause = copyChain(e.cause)
+		return &c
+	}
+	return err
+}
+
+func copyLabels(labels map[string]string) map[string]string {
+	if labels == nil {
+		return nil
+	}
+	c := make(map[string]string, len(labels))
+	for k, v := range labels {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,127 @@
+package terrors
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime/pprof"
+	"testing"
+)
+
+func labeled(err error) error {
+	var labeled error
+	pprof.Do(context.Background(), pprof.Labels("tenant", "acme"), func(ctx context.Context) {
+		labeled = WithLabels(ctx, err)
+	})
+	return labeled
+}
+
+func TestCloneWithMessage(t *testing.T) {
+	CaptureGoroutineLabels(true)
+	orig := labeled(Wrap(TypeInternal, New(TypeNotExist, "missing"), "load"))
+	CaptureGoroutineLabels(false)
+	want := fmt.Sprint(Labels(orig))
+
+	c := CloneWithMessage(orig, "reload")
+	if c.Error() != "reload: missing" || orig.Error() != "load: missing" {
+		t.Errorf("clone %q, original %q", c.Error(), orig.Error())
+	}
+	if fmt.Sprint(Labels(c)) != want {
+		t.Errorf("Labels(clone) = %v, want %v", Labels(c), want)
+	}
+
+	for e := c; e != nil; e = unwrapOnce(e) {
+		if l, ok := e.(interface{ labelSet() map[string]string }); ok && l.labelSet() != nil {
+			l.labelSet()["tenant"] = "globex"
+			l.labelSet()["goroutine"] = "0"
+		}
+	}
+	if got := fmt.Sprint(Labels(orig)); got != want {
+		t.Errorf("Labels(original) = %s after mutating the clone, want %s", got, want)
+	}
+}
+
+func TestCloneWithType(t *testing.T) {
+	orig := Wrap(TypeInternal, fmt.Errorf("read: %w", New(TypeNotExist, "missing")), "load")
+
+	c := CloneWithType(orig, TypeUnavailable)
+	if TypeOf(c) != TypeUnavailable || RootType(c) != TypeNotExist {
+		t.Errorf("clone types = %v/%v", TypeOf(c), RootType(c))
+	}
+	if TypeOf(orig) != TypeInternal || c.Error() != orig.Error() {
+		t.Errorf("original type %v, clone message %q", TypeOf(orig), c.Error())
+	}
+}
+
+func TestCloneForeign(t *testing.T) {
+	if c := CloneWithMessage(io.EOF, "other"); c != io.EOF {
+		t.Errorf("CloneWithMessage(io.EOF) = %v", c)
+	}
+	if c := CloneWithType(io.EOF, TypeInvalid); c != io.EOF {
+		t.Errorf("CloneWithType(io.EOF) = %v", c)
+	}
+}
+
+// setLabels sets the labels of every layer of err's chain that holds some.
+func setLabels(err error, k, v string) {
+	for e := err; e != nil; e = unwrapOnce(e) {
+		if l, ok := e.(interface{ labelSet() map[string]string }); ok && l.labelSet() != nil {
+			l.labelSet()[k] = v
+		}
+	}
+}
+
+// copied is a chain with labels, a secondary error and a foreign cause.
+func copied() error {
+	CaptureGoroutineLabels(true)
+	defer CaptureGoroutineLabels(false)
+
+	err := Wrap(TypeInternal, fmt.Errorf("read: %w", io.EOF), "load")
+	err = WithSecondary(err, New(TypeUnavailable, "close"))
+	return labeled(err)
+}
+
+func TestCopy(t *testing.T) {
+	for _, mutateCopy := range []bool{true, false} {
+		orig := copied()
+		c := Copy(orig)
+
+		mutated, other := orig, c
+		if mutateCopy {
+			mutated, other = c, orig
+		}
+
+		setLabels(mutated, "tenant", "globex")
+		mutated.(*withLabels).cause.(*withSecondary).secondary[0] = io.ErrClosedPipe
+
+		if got := Labels(mutated)["tenant"]; got != "globex" {
+			t.Fatalf("mutate copy %v: labels of the mutated error = %v", mutateCopy, Labels(mutated))
+		}
+		if got := Labels(other)["tenant"]; got != "acme" {
+			t.Errorf("mutate copy %v: labels of the other error = %v, want tenant=acme", mutateCopy, Labels(other))
+		}
+		if got := fmt.Sprint(Secondary(other)); got != "[close]" {
+			t.Errorf("mutate copy %v: secondary of the other error = %s, want [close]", mutateCopy, got)
+		}
+	}
+}
+
+func TestCopyShares(t *testing.T) {
+	orig := copied()
+	c := Copy(orig)
+
+	if c == orig || c.Error() != orig.Error() || TypeOf(c) != TypeOf(orig) {
+		t.Errorf("Copy() = %q, want a copy of %q", c, orig)
+	}
+	if Cause(c) != io.EOF {
+		t.Errorf("Cause(copy) = %v, want the shared foreign cause", Cause(c))
+	}
+	st, _ := StackTrace(c)
+	ost, _ := StackTrace(orig)
+	if &st[0] != &ost[0] {
+		t.Error("the copy does not share the stack")
+	}
+	if Copy(nil) != nil {
+		t.Error("Copy(nil) != nil")
+	}
+}
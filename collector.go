@@ -0,0 +1,180 @@
+package terrors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// DefaultCollectorLimit is the number of errors kept by WithCollector.
+const DefaultCollectorLimit = 100
+
+type collectorKey struct{}
+
+type collector struct {
+	mu     sync.Mutex
+	limit  int
+	dedup  bool
+	errs   []error
+	counts []int
+	groups map[string]int // fingerprint to index in errs, with dedup
+	total  int
+}
+
+type CollectorOption func(*collector)
+
+// WithDedup groups the reported errors by Fingerprint: only the first error
+// of each group is kept, along with the number of errors reported for it.
+// The limit then applies to the number of groups.
+func WithDedup() CollectorOption {
+	return func(c *collector) {
+		c.dedup = true
+		c.groups = map[string]int{}
+	}
+}
+
+// WithCollector returns a context collecting the errors passed to Report,
+// for middleware inspecting every error of a request with Collected, even
+// those that were handled. The first DefaultCollectorLimit errors are kept.
+func WithCollector(ctx context.Context, opts ...CollectorOption) context.Context {
+	return WithCollectorLimit(ctx, DefaultCollectorLimit, opts...)
+}
+
+// WithCollectorLimit is like WithCollector but keeps the first limit errors.
+func WithCollectorLimit(ctx context.Context, limit int, opts ...CollectorOption) context.Context {
+	c := &collector{limit: limit}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return context.WithValue(ctx, collectorKey{}, c)
+}
+
+// Report adds err to the collector of ctx, if any. It is safe for concurrent
+// use.
+func Report(ctx context.Context, err error) {
+	c, ok := ctx.Value(collectorKey{}).(*collector)
+	if !ok || err == nil {
+		return
+	}
+
+	var key string
+	if c.dedup {
+		key = Fingerprint(err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.total++
+	if i, ok := c.groups[key]; ok && c.dedup {
+		c.counts[i]++
+		return
+	}
+	if len(c.errs) >= c.limit {
+		return
+	}
+	if c.dedup {
+		c.groups[key] = len(c.errs)
+	}
+	c.errs = append(c.errs, err)
+	c.counts = append(c.counts, 1)
+}
+
+// Collected returns the errors reported to the collector of ctx so far, in
+// the order they were reported. With WithDedup, only the first error of each
+// group is returned.
+func Collected(ctx context.Context) []error {
+	c, ok := ctx.Value(collectorKey{}).(*collector)
+	if !ok {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]error(nil), c.errs...)
+}
+
+// CollectedErr joins the errors returned by Collected into one error, or
+// returns nil when there are none. The message of an error reported n times
+// under WithDedup ends with " (×n)", as in "connection refused (×10000)".
+func CollectedErr(ctx context.Context) error {
+	c, ok := ctx.Value(collectorKey{}).(*collector)
+	if !ok {
+		return nil
+	}
+
+	c.mu.Lock()
+	errs := make([]error, len(c.errs))
+	for i, err := range c.errs {
+		errs[i] = err
+		if c.counts[i] > 1 {
+			errs[i] = &repeated{err: err, n: c.counts[i]}
+		}
+	}
+	c.mu.Unlock()
+
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	return stderrors.Join(errs...)
+}
+
+// CollectedLen returns the number of errors reported to the collector of ctx,
+// including those that were not kept.
+func CollectedLen(ctx context.Context) int {
+	c, ok := ctx.Value(collectorKey{}).(*collector)
+	if !ok {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.total
+}
+
+// CollectedDistinct returns the number of errors kept by the collector of
+// ctx: the number of groups with WithDedup.
+func CollectedDistinct(ctx context.Context) int {
+	c, ok := ctx.Value(collectorKey{}).(*collector)
+	if !ok {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.errs)
+}
+
+// repeated is the first error of a group of n, see CollectedErr.
+type repeated struct {
+	err error
+	n   int
+}
+
+func (r *repeated) Error() string {
+	return fmt.Sprintf("%s (×%d)", r.err.Error(), r.n)
+}
+
+func (r *repeated) Unwrap() error {
+	return r.err
+}
+
+func (r *repeated) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			fmt.Fprintf(s, "%+v\nreported %d times", r.err, r.n)
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, r.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", r.Error())
+	}
+}
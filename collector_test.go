@@ -0,0 +1,127 @@
+package terrors
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func refused() error {
+	return Wrap(TypeUnavailable, io.ErrClosedPipe, "connection refused")
+}
+
+func TestCollector(t *testing.T) {
+	ctx := WithCollectorLimit(context.Background(), 2)
+	first, second := New(TypeInvalid, "a"), New(TypeInvalid, "b")
+	Report(ctx, first)
+	Report(ctx, nil)
+	Report(ctx, second)
+	Report(ctx, New(TypeInvalid, "c"))
+
+	if got := Collected(ctx); len(got) != 2 || got[0] != first || got[1] != second {
+		t.Errorf("Collected() = %v", got)
+	}
+	if CollectedLen(ctx) != 3 || CollectedDistinct(ctx) != 2 {
+		t.Errorf("CollectedLen() = %d, CollectedDistinct() = %d", CollectedLen(ctx), CollectedDistinct(ctx))
+	}
+	if got := CollectedErr(ctx); got == nil || got.Error() != "a\nb" {
+		t.Errorf("CollectedErr() = %v", got)
+	}
+}
+
+func TestCollectorWithoutCollector(t *testing.T) {
+	ctx := context.Background()
+	Report(ctx, io.EOF)
+	if Collected(ctx) != nil || CollectedErr(ctx) != nil || CollectedLen(ctx) != 0 || CollectedDistinct(ctx) != 0 {
+		t.Error("a context without collector collected errors")
+	}
+}
+
+func TestCollectorDedup(t *testing.T) {
+	ctx := WithCollector(context.Background(), WithDedup())
+
+	var firsts []error
+	for i := 0; i < 10000; i++ {
+		err := refused()
+		if i == 0 {
+			firsts = append(firsts, err)
+		}
+		Report(ctx, err)
+	}
+	other := New(TypeNotExist, "item 7 not found")
+	firsts = append(firsts, other)
+	Report(ctx, other)
+
+	got := Collected(ctx)
+	if len(got) != 2 || got[0] != firsts[0] || got[1] != firsts[1] {
+		t.Errorf("Collected() = %v, want the first error of each group", got)
+	}
+	if CollectedLen(ctx) != 10001 || CollectedDistinct(ctx) != 2 {
+		t.Errorf("CollectedLen() = %d, CollectedDistinct() = %d", CollectedLen(ctx), CollectedDistinct(ctx))
+	}
+
+	err := CollectedErr(ctx)
+	if want := "connection refused: io: read/write on closed pipe (×10000)\nitem 7 not found"; err.Error() != want {
+		t.Errorf("CollectedErr() = %q, want %q", err, want)
+	}
+	if !HasType(err, TypeUnavailable) || !HasType(err, TypeNotExist) {
+		t.Error("CollectedErr() lost the types")
+	}
+	if st, _ := StackTrace(Collected(ctx)[0]); fmt.Sprintf("%n", st[1]) != "TestCollectorDedup" {
+		t.Errorf("representative stack = %v", st)
+	}
+	joined := err.(interface{ Unwrap() []error }).Unwrap()
+	if s := fmt.Sprintf("%+v", joined[0]); !strings.Contains(s, "TestCollectorDedup") || !strings.HasSuffix(s, "\nreported 10000 times") {
+		t.Errorf("%%+v lacks the count: %s", s)
+	}
+}
+
+func TestCollectorDedupWithoutStack(t *testing.T) {
+	ctx := WithCollector(context.Background(), WithDedup())
+	Report(ctx, io.EOF)
+	Report(ctx, fmt.Errorf("EOF"))
+	Report(ctx, fmt.Errorf("other"))
+	Report(ctx, WithStack(TypeInternal, io.EOF))
+
+	if got := CollectedErr(ctx).Error(); got != "EOF (×2)\nother\nEOF" {
+		t.Errorf("CollectedErr() = %q", got)
+	}
+}
+
+func TestCollectorDedupLimit(t *testing.T) {
+	ctx := WithCollectorLimit(context.Background(), 1, WithDedup())
+	for i := 0; i < 2; i++ {
+		Report(ctx, refused())
+		if i == 0 {
+			Report(ctx, New(TypeNotExist, "dropped"))
+		}
+	}
+
+	if CollectedDistinct(ctx) != 1 || CollectedLen(ctx) != 3 || !strings.HasSuffix(CollectedErr(ctx).Error(), "(×2)") {
+		t.Errorf("got %d/%d %q", CollectedDistinct(ctx), CollectedLen(ctx), CollectedErr(ctx))
+	}
+}
+
+func TestCollectorConcurrent(t *testing.T) {
+	ctx := WithCollector(context.Background(), WithDedup())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				Report(ctx, refused())
+				_ = CollectedErr(ctx)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if CollectedLen(ctx) != 800 || CollectedDistinct(ctx) != 1 {
+		t.Errorf("CollectedLen() = %d, CollectedDistinct() = %d", CollectedLen(ctx), CollectedDistinct(ctx))
+	}
+}
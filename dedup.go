@@ -0,0 +1,31 @@
+package terrors
+
+import (
+	"sync/atomic"
+)
+
+var wrapDedup atomic.Bool
+
+// SetWrapDedup makes Wrap, Wrapf and WithMessage leave out their message
+// when it is exactly the message of the layer they wrap, the nearest one
+// contributing a message, so that retries do not produce
+// "query user: query user: ...". The type and stack are still added. The
+// comparison is exact and case-sensitive: "query user" and "query users"
+// are both kept. It is off by default.
+func SetWrapDedup(enabled bool) {
+	wrapDedup.Store(enabled)
+}
+
+func duplicateMessage(err error, msg string) bool {
+	if !wrapDedup.Load() || msg == "" {
+		return false
+	}
+
+	for e := err; e != nil; e = unwrapOnce(e) {
+		own, split := ownMessage(e)
+		if own != "" || !split {
+			return own == msg
+		}
+	}
+	return false
+}
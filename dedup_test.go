@@ -0,0 +1,109 @@
+package terrors
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestWrapDedup(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func() error
+		on    string // Error() with SetWrapDedup(true)
+		off   string
+	}{
+		{
+			"duplicate",
+			func() error {
+				return Wrap(TypeInternal, Wrap(TypeInternal, New(TypeNotExist, "not found"), "query user"), "query user")
+			},
+			"query user: not found",
+			"query user: query user: not found",
+		},
+		{
+			"duplicate of the root",
+			func() error { return Wrap(TypeNotExist, New(TypeNotExist, "not found"), "not found") },
+			"not found",
+			"not found: not found",
+		},
+		{
+			"WithMessage and Wrapf",
+			func() error {
+				err := WithMessage(TypeInternal, New(TypeNotExist, "not found"), "load 7")
+				return Wrapf(TypeInternal, WithMessage(TypeInvalid, err, "load 7"), "load %d", 7)
+			},
+			"load 7: not found",
+			"load 7: load 7: load 7: not found",
+		},
+		{
+			"through layers without a message",
+			func() error {
+				return Wrap(TypeInternal, WithOp(WithStack(TypeInternal, Wrap(TypeInternal, New(TypeNotExist, "x"), "retry")), "op"), "retry")
+			},
+			"retry: x",
+			"retry: retry: x",
+		},
+		{
+			"near duplicates",
+			func() error {
+				err := Wrap(TypeInternal, New(TypeNotExist, "not found"), "query user")
+				err = Wrap(TypeInternal, err, "query users")
+				err = Wrap(TypeInternal, err, "Query users")
+				return Wrap(TypeInternal, err, "query users ")
+			},
+			"query users : Query users: query users: query user: not found",
+			"query users : Query users: query users: query user: not found",
+		},
+		{
+			"interleaved",
+			func() error {
+				err := Wrap(TypeInternal, New(TypeNotExist, "not found"), "a")
+				err = Wrap(TypeInternal, err, "b")
+				return Wrap(TypeInternal, err, "a")
+			},
+			"a: b: a: not found",
+			"a: b: a: not found",
+		},
+		{
+			"foreign layer",
+			func() error {
+				return Wrap(TypeInternal, fmt.Errorf("retry: %w", Wrap(TypeInternal, New(TypeNotExist, "x"), "fetch")), "retry")
+			},
+			"retry: fetch: x",
+			"retry: retry: fetch: x",
+		},
+	}
+
+	t.Cleanup(func() { SetWrapDedup(false) })
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetWrapDedup(false)
+			if got := tt.build().Error(); got != tt.off {
+				t.Errorf("dedup off: Error() = %q, want %q", got, tt.off)
+			}
+
+			SetWrapDedup(true)
+			err := tt.build()
+			if got := err.Error(); got != tt.on {
+				t.Errorf("dedup on: Error() = %q, want %q", got, tt.on)
+			}
+			if TypeOf(err) != TypeOf(tt.build()) {
+				t.Errorf("dedup on: TypeOf() = %v", TypeOf(err))
+			}
+		})
+	}
+}
+
+func TestWrapDedupKeepsTypeAndStack(t *testing.T) {
+	SetWrapDedup(true)
+	t.Cleanup(func() { SetWrapDedup(false) })
+
+	inner := New(TypeNotExist, "not found")
+	err := Wrap(TypeUnavailable, inner, "not found")
+	if err.Error() != "not found" || TypeOf(err) != TypeUnavailable || RootType(err) != TypeNotExist {
+		t.Errorf("Wrap() = %v: %q", TypeOf(err), err)
+	}
+	if got := len(AllStacks(err)); got != 2 {
+		t.Errorf("%d stacks, want 2", got)
+	}
+}
@@ -0,0 +1,62 @@
+package terrors
+
+import (
+	"fmt"
+	"io"
+)
+
+// WithDetails attaches d to err. Details are looked up by their type with
+// Details, so a chain holds at most one visible payload per type: the
+// outermost. They do not change err's message or type, and appear in Encode
+// and in httperr bodies so that serialization formats marshal them by
+// reflection. Use Labels for free-form string annotations.
+func WithDetails[T any](err error, d T) error {
+	if err == nil {
+		return nil
+	}
+	return &withDetails{cause: err, details: d}
+}
+
+func Details[T any](err error) (T, bool) {
+	var found T
+	var ok bool
+	walk(err, func(e error) bool {
+		if w, isDetails := e.(*withDetails); isDetails {
+			found, ok = w.details.(T)
+		}
+		return !ok
+	})
+	return found, ok
+}
+
+type withDetails struct {
+	cause   error
+	details interface{}
+}
+
+func (w *withDetails) Error() string {
+	return w.cause.Error()
+}
+
+func (w *withDetails) Cause() error {
+	return w.cause
+}
+
+func (w *withDetails) Unwrap() error {
+	return w.cause
+}
+
+func (w *withDetails) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			formatVerbose(s, w)
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, w.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", w.Error())
+	}
+}
@@ -0,0 +1,71 @@
+package terrors
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type quotaExceeded struct {
+	Limit   int       `json:"limit"`
+	Used    int       `json:"used"`
+	ResetAt time.Time `json:"reset_at"`
+}
+
+func TestDetails(t *testing.T) {
+	inner := quotaExceeded{Limit: 10, Used: 10}
+	outer := quotaExceeded{Limit: 20, Used: 21}
+	err := WithDetails(Wrap(TypeUnavailable, WithDetails(New(TypeInternal, "quota"), inner), "call"), outer)
+
+	if got, ok := Details[quotaExceeded](err); !ok || got != outer {
+		t.Errorf("Details() = %v, %v; want the outermost payload", got, ok)
+	}
+	if got, ok := Details[string](err); ok || got != "" {
+		t.Errorf("Details[string]() = %q, %v", got, ok)
+	}
+	if err.Error() != "call: quota" || TypeOf(err) != TypeUnavailable {
+		t.Errorf("WithDetails changed the error: %q %v", err, TypeOf(err))
+	}
+}
+
+func TestEncodeDetails(t *testing.T) {
+	reset := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	err := Wrap(TypeUnavailable, WithDetails(New(TypeInternal, "quota"), quotaExceeded{Limit: 10, Used: 11, ResetAt: reset}), "call")
+
+	b, jerr := json.Marshal(Encode(err, false))
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+	want := `{"cause":{"cause":{"message":"quota","type":"internal"},"details":{"limit":10,"used":11,"reset_at":"2026-01-02T03:04:05Z"}},"message":"call","type":"unavailable"}`
+	if string(b) != want {
+		t.Errorf("JSON = %s, want %s", b, want)
+	}
+
+	var m map[string]interface{}
+	if jerr := json.Unmarshal(b, &m); jerr != nil {
+		t.Fatal(jerr)
+	}
+	decoded, derr := Decode(m)
+	if derr != nil {
+		t.Fatal(derr)
+	}
+	details, ok := Details[map[string]interface{}](decoded)
+	if !ok || details["limit"] != float64(10) || details["reset_at"] != "2026-01-02T03:04:05Z" {
+		t.Errorf("decoded details = %v, %v", details, ok)
+	}
+	if decoded.Error() != err.Error() || TypeOf(decoded) != TypeUnavailable {
+		t.Errorf("decoded %q %v", decoded, TypeOf(decoded))
+	}
+}
+
+func TestBinaryOmitsDetails(t *testing.T) {
+	err := WithDetails(New(TypeInternal, "quota"), quotaExceeded{Limit: 10})
+
+	decoded, derr := DecodeBinary(AppendBinary(nil, err))
+	if derr != nil {
+		t.Fatal(derr)
+	}
+	if _, ok := Details[quotaExceeded](decoded); ok || decoded.Error() != "quota" {
+		t.Errorf("decoded %q with details %v", decoded, ok)
+	}
+}
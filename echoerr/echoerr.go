@@ -0,0 +1,117 @@
+package echoerr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/thamaji/terrors"
+)
+
+type Option func(*options)
+
+type options struct {
+	logger func(c echo.Context, err error)
+	body   func(c echo.Context, err error, status int) interface{}
+}
+
+// WithLogger registers fn to be called for errors answered with a 5xx status
+// that are not marked handled. Log it with %+v to get the full chain and
+// stacks.
+func WithLogger(fn func(c echo.Context, err error)) Option {
+	return func(o *options) {
+		o.logger = fn
+	}
+}
+
+// WithBody replaces the default JSON body with the value returned by fn.
+func WithBody(fn func(c echo.Context, err error, status int) interface{}) Option {
+	return func(o *options) {
+		o.body = fn
+	}
+}
+
+func HTTPErrorHandler(opts ...Option) echo.HTTPErrorHandler {
+	o := &options{body: Body}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return func(err error, c echo.Context) {
+		if err == nil {
+			return
+		}
+
+		status := Status(err)
+		if status >= http.StatusInternalServerError && o.logger != nil && !terrors.IsHandled(err) {
+			o.logger(c, err)
+		}
+
+		if c.Response().Committed {
+			return
+		}
+
+		var werr error
+		if c.Request().Method == http.MethodHead {
+			werr = c.NoContent(status)
+		} else {
+			werr = c.JSON(status, o.body(c, err, status))
+		}
+		if werr != nil && o.logger != nil {
+			o.logger(c, werr)
+		}
+	}
+}
+
+func Status(err error) int {
+	if _, ok := terrors.TypeOk(err); !ok {
+		var he *echo.HTTPError
+		if errors.As(err, &he) {
+			return he.Code
+		}
+	}
+	return terrors.HTTPStatus(err)
+}
+
+// Body is the default response body: the type name, a message safe to show to
+// clients, and the error and request ids when they are set.
+func Body(c echo.Context, err error, status int) interface{} {
+	t, typed := terrors.TypeOk(err)
+	if !typed {
+		t = terrors.TypeOf(err)
+	}
+	message := err.Error()
+
+	var he *echo.HTTPError
+	if !typed && errors.As(err, &he) {
+		t = terrors.TypeOfHTTPStatus(he.Code)
+		if s, ok := he.Message.(string); ok {
+			message = s
+		} else {
+			message = fmt.Sprint(he.Message)
+		}
+	}
+
+	if status >= http.StatusInternalServerError {
+		message = http.StatusText(status)
+	}
+
+	body := map[string]interface{}{
+		"type":    t.String(),
+		"message": message,
+	}
+	if id := terrors.ID(err); id != "" {
+		body["error_id"] = id
+	}
+
+	requestID := c.Request().Header.Get(echo.HeaderXRequestID)
+	if requestID == "" {
+		requestID = c.Response().Header().Get(echo.HeaderXRequestID)
+	}
+	if requestID != "" {
+		body["request_id"] = requestID
+	}
+
+	return body
+}
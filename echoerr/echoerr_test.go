@@ -0,0 +1,112 @@
+package echoerr
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/thamaji/terrors"
+)
+
+func serve(t *testing.T, method string, err error, opts ...Option) (*httptest.ResponseRecorder, map[string]interface{}) {
+	t.Helper()
+
+	e := echo.New()
+	req := httptest.NewRequest(method, "/", nil)
+	req.Header.Set(echo.HeaderXRequestID, "req-1")
+	rec := httptest.NewRecorder()
+	HTTPErrorHandler(opts...)(err, e.NewContext(req, rec))
+
+	var body map[string]interface{}
+	if rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return rec, body
+}
+
+func TestHTTPErrorHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		typ     string
+		message string
+	}{
+		{"typed", terrors.New(terrors.TypeNotExist, "user not found"), http.StatusNotFound, "not_exist", "user not found"},
+		{"internal", terrors.New(terrors.TypeInternal, "db password is hunter2"), http.StatusInternalServerError, "internal", "Internal Server Error"},
+		{"plain", errors.New("boom"), http.StatusInternalServerError, "unknown", "Internal Server Error"},
+		{"echo error", echo.NewHTTPError(http.StatusConflict, "already taken"), http.StatusConflict, "exist", "already taken"},
+		{"echo not found", echo.ErrNotFound, http.StatusNotFound, "not_exist", "Not Found"},
+		{"typed wrapping echo error", terrors.Wrap(terrors.TypeInvalid, echo.ErrNotFound, "route"), http.StatusBadRequest, "invalid", "route: code=404, message=Not Found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, body := serve(t, http.MethodGet, tt.err)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if body["type"] != tt.typ || body["message"] != tt.message || body["request_id"] != "req-1" {
+				t.Errorf("body = %v", body)
+			}
+		})
+	}
+}
+
+func TestHTTPErrorHandlerErrorID(t *testing.T) {
+	err := terrors.New(terrors.TypeInvalid, "bad input")
+	_, body := serve(t, http.MethodGet, err)
+	if body["error_id"] != terrors.ID(err) {
+		t.Errorf("error_id = %v, want %s", body["error_id"], terrors.ID(err))
+	}
+}
+
+func TestHTTPErrorHandlerHead(t *testing.T) {
+	rec, _ := serve(t, http.MethodHead, terrors.New(terrors.TypeNotExist, "user not found"))
+	if rec.Code != http.StatusNotFound || rec.Body.Len() != 0 {
+		t.Errorf("got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHTTPErrorHandlerLogger(t *testing.T) {
+	var logged []error
+	logger := WithLogger(func(c echo.Context, err error) { logged = append(logged, err) })
+
+	serve(t, http.MethodGet, terrors.New(terrors.TypeInvalid, "bad input"), logger)
+	serve(t, http.MethodGet, terrors.MarkHandled(terrors.New(terrors.TypeInternal, "logged already")), logger)
+	internal := terrors.New(terrors.TypeInternal, "db down")
+	serve(t, http.MethodGet, internal, logger)
+
+	if len(logged) != 1 || logged[0] != internal {
+		t.Errorf("logged %v, want only %v", logged, internal)
+	}
+}
+
+func TestHTTPErrorHandlerBody(t *testing.T) {
+	body := WithBody(func(c echo.Context, err error, status int) interface{} {
+		return map[string]interface{}{"error": status}
+	})
+	_, got := serve(t, http.MethodGet, terrors.New(terrors.TypeConflict, "busy"), body)
+	if got["error"] != float64(http.StatusConflict) {
+		t.Errorf("body = %v", got)
+	}
+}
+
+func TestHTTPErrorHandlerCommitted(t *testing.T) {
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+	if err := c.String(http.StatusOK, "partial"); err != nil {
+		t.Fatal(err)
+	}
+
+	HTTPErrorHandler()(terrors.New(terrors.TypeInternal, "late"), c)
+	if rec.Code != http.StatusOK || rec.Body.String() != "partial" {
+		t.Errorf("got %d %q", rec.Code, rec.Body.String())
+	}
+}
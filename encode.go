@@ -0,0 +1,179 @@
+package terrors
+
+// Encode returns err's chain as nested maps that any serialization format
+// can carry: every layer is a map with its type name under "type", the
+// message it contributes under "message", the errors it joins under "errors",
+// the errors attached with WithSecondary under "secondary", the payload
+// attached with WithDetails under "details" and the next layer under
+// "cause". Payloads are kept as is, for the serialization format to marshal
+// them by reflection.
+// The outermost map also holds the labels under "labels" and, when stack is
+// set, the innermost stack under "stack" as StackLines does. A nil error
+// yields nil.
+func Encode(err error, stack bool) map[string]interface{} {
+	if err == nil {
+		return nil
+	}
+
+	top := encodeLayers(chainLayers(err))
+	if labels := Labels(err); len(labels) > 0 {
+		top["labels"] = labels
+	}
+	if st := innermostStack(err); stack && len(st) > 0 {
+		top["stack"] = stackLines(st, 0)
+	}
+	return top
+}
+
+// Decode rebuilds an error from the maps returned by Encode, after they went
+// through a serialization format: nested maps may be map[string]interface{}
+// and strings may be held by interface{} values. Stacks are not restored, and
+// details payloads are restored as the format decoded them, such as
+// map[string]interface{} for JSON objects.
+// Malformed maps yield a TypeInvalid error. A nil map yields a nil error.
+func Decode(m map[string]interface{}) (error, error) {
+	if m == nil {
+		return nil, nil
+	}
+
+	layers, derr := decodeLayers(m)
+	if derr != nil {
+		return nil, derr
+	}
+	err := buildChain(layers)
+
+	switch v := m["labels"].(type) {
+	case nil:
+	case map[string]string:
+		err = &withLabels{cause: err, labels: copyLabels(v)}
+	case map[string]interface{}:
+		labels := make(map[string]string, len(v))
+		for k, value := range v {
+			s, ok := value.(string)
+			if !ok {
+				return nil, Errorf(TypeInvalid, "terrors: label %q is not a string", k)
+			}
+			labels[k] = s
+		}
+		err = &withLabels{cause: err, labels: labels}
+	default:
+		return nil, Errorf(TypeInvalid, "terrors: labels %v are not a map", v)
+	}
+
+	return err, nil
+}
+
+func encodeLayers(layers []chainLayer) map[string]interface{} {
+	var top, parent map[string]interface{}
+	for _, l := range layers {
+		m := map[string]interface{}{}
+		if l.flags&binaryTyped != 0 {
+			m["type"] = l.t.String()
+		}
+		if l.flags&binaryMessage != 0 {
+			m["message"] = l.msg
+		}
+		if l.flags&binaryJoined != 0 {
+			joined := make([]interface{}, len(l.joined))
+			for i, j := range l.joined {
+				joined[i] = encodeLayers(j)
+			}
+			m["errors"] = joined
+		}
+		if l.flags&binarySecondary != 0 {
+			secondary := make([]interface{}, len(l.secondary))
+			for i, s := range l.secondary {
+				secondary[i] = encodeLayers(s)
+			}
+			m["secondary"] = secondary
+		}
+		if l.details != nil {
+			m["details"] = l.details
+		}
+		if parent == nil {
+			top = m
+		} else {
+			parent["cause"] = m
+		}
+		parent = m
+	}
+	return top
+}
+
+func decodeLayers(m map[string]interface{}) ([]chainLayer, error) {
+	var layers []chainLayer
+	for layer := m; layer != nil; {
+		var l chainLayer
+		if v, ok := layer["type"]; ok {
+			name, ok := v.(string)
+			if !ok {
+				return nil, Errorf(TypeInvalid, "terrors: type %v is not a string", v)
+			}
+			if l.t, ok = ParseType(name); !ok {
+				return nil, Errorf(TypeInvalid, "terrors: unknown type %q", name)
+			}
+			l.flags |= binaryTyped
+		}
+		if v, ok := layer["message"]; ok {
+			msg, ok := v.(string)
+			if !ok {
+				return nil, Errorf(TypeInvalid, "terrors: message %v is not a string", v)
+			}
+			l.msg = msg
+			l.flags |= binaryMessage
+		}
+		if v, ok := layer["errors"]; ok {
+			joined, err := decodeList(v, "errors")
+			if err != nil {
+				return nil, err
+			}
+			l.joined = joined
+			l.flags |= binaryJoined
+		}
+		if v, ok := layer["secondary"]; ok {
+			secondary, err := decodeList(v, "secondary")
+			if err != nil {
+				return nil, err
+			}
+			if len(secondary) > 0 {
+				l.secondary = secondary
+				l.flags |= binarySecondary
+			}
+		}
+		if v, ok := layer["details"]; ok && v != nil {
+			l.details = v
+		}
+		layers = append(layers, l)
+
+		v, ok := layer["cause"]
+		if !ok {
+			break
+		}
+		if layer, ok = v.(map[string]interface{}); !ok {
+			return nil, Errorf(TypeInvalid, "terrors: cause %v is not a map", v)
+		}
+	}
+	return layers, nil
+}
+
+// decodeList decodes the list of chains held by the key of a layer.
+func decodeList(v interface{}, key string) ([][]chainLayer, error) {
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, Errorf(TypeInvalid, "terrors: %s %v are not a list", key, v)
+	}
+
+	var chains [][]chainLayer
+	for _, e := range list {
+		m, ok := e.(map[string]interface{})
+		if !ok {
+			return nil, Errorf(TypeInvalid, "terrors: %s error %v is not a map", key, e)
+		}
+		layers, err := decodeLayers(m)
+		if err != nil {
+			return nil, err
+		}
+		chains = append(chains, layers)
+	}
+	return chains, nil
+}
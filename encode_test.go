@@ -0,0 +1,55 @@
+package terrors
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"testing"
+)
+
+func TestEncodeJoin(t *testing.T) {
+	err := Wrap(TypeConflict, stderrors.Join(New(TypeInternal, "a"), io.EOF), "save")
+
+	decoded, derr := Decode(Encode(err, false))
+	if derr != nil {
+		t.Fatal(derr)
+	}
+	if decoded.Error() != err.Error() {
+		t.Errorf("Error() = %q, want %q", decoded.Error(), err.Error())
+	}
+	if got, want := typesOf(decoded), typesOf(err); got != want {
+		t.Errorf("types of the tree = %s, want %s", got, want)
+	}
+
+	if TypeOf(Cause(decoded)) != TypeOf(err.(*wrapped).cause) {
+		t.Errorf("TypeOf(join) = %v, want %v", TypeOf(Cause(decoded)), TypeOf(err.(*wrapped).cause))
+	}
+}
+
+func TestEncodeSecondary(t *testing.T) {
+	err := secondaryErr()
+
+	data, jerr := json.Marshal(Encode(err, false))
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+	var m map[string]interface{}
+	if jerr := json.Unmarshal(data, &m); jerr != nil {
+		t.Fatal(jerr)
+	}
+
+	decoded, derr := Decode(m)
+	if derr != nil {
+		t.Fatal(derr)
+	}
+	if decoded.Error() != err.Error() || typesOf(decoded) != typesOf(err) {
+		t.Errorf("decoded %q %s, want %q %s", decoded, typesOf(decoded), err, typesOf(err))
+	}
+	if got, want := secondaryOf(decoded), secondaryOf(err); got != want {
+		t.Errorf("Secondary() = %s, want %s\n%s", got, want, data)
+	}
+
+	if _, derr := Decode(map[string]interface{}{"message": "x", "secondary": "y"}); TypeOf(derr) != TypeInvalid {
+		t.Errorf("Decode() of a malformed secondary list = %v", derr)
+	}
+}
@@ -0,0 +1,76 @@
+package terrors
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+var (
+	exitMu      sync.RWMutex
+	exitVerbose bool
+	exitCodes   = map[Type]int{
+		TypeNotError:     0,
+		TypeUnknown:      1,
+		TypeInternal:     1,
+		TypeInvalid:      65, // EX_DATAERR
+		TypeNotExist:     66, // EX_NOINPUT
+		TypeExist:        73, // EX_CANTCREAT
+		TypeUnauthorized: 77, // EX_NOPERM
+		TypePermission:   77, // EX_NOPERM
+		TypeConflict:     75, // EX_TEMPFAIL
+		TypeTimeout:      75, // EX_TEMPFAIL
+		TypeUnavailable:  69, // EX_UNAVAILABLE
+		TypeCanceled:     130,
+	}
+)
+
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	return typeExitCode(TypeOf(err))
+}
+
+func typeExitCode(t Type) int {
+	exitMu.RLock()
+	code, ok := exitCodes[t]
+	exitMu.RUnlock()
+	if !ok {
+		return 1
+	}
+
+	return code
+}
+
+func SetExitCode(t Type, code int) {
+	exitMu.Lock()
+	exitCodes[t] = code
+	exitMu.Unlock()
+}
+
+func SetExitVerbose(verbose bool) {
+	exitMu.Lock()
+	exitVerbose = verbose
+	exitMu.Unlock()
+}
+
+func Exit(err error) {
+	exitMu.RLock()
+	verbose := exitVerbose
+	exitMu.RUnlock()
+
+	os.Exit(printExit(os.Stderr, err, verbose))
+}
+
+func printExit(w io.Writer, err error, verbose bool) int {
+	if err == nil {
+		return 0
+	}
+
+	fmt.Fprintln(w, Render(err, verbose))
+
+	return ExitCode(err)
+}
@@ -0,0 +1,78 @@
+package terrors
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, 0},
+		{"untyped", errors.New("boom"), 1},
+		{"invalid", New(TypeInvalid, "bad flag"), 65},
+		{"not exist", New(TypeNotExist, "no such file"), 66},
+		{"permission", New(TypePermission, "denied"), 77},
+		{"unavailable", Wrap(TypeUnavailable, errors.New("refused"), "dial"), 69},
+		{"canceled", Wrap(TypeCanceled, context.Canceled, "interrupted"), 130},
+		{"outer type wins", Wrap(TypeInvalid, New(TypeNotExist, "no such file"), "load"), 65},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetExitCode(t *testing.T) {
+	defer SetExitCode(TypeConflict, ExitCode(New(TypeConflict, "")))
+
+	SetExitCode(TypeConflict, 42)
+	if got := ExitCode(New(TypeConflict, "busy")); got != 42 {
+		t.Errorf("ExitCode() = %d, want 42", got)
+	}
+	if got := ExitCode(New(TypeTimeout, "slow")); got != 75 {
+		t.Errorf("ExitCode() of another type = %d, want 75", got)
+	}
+}
+
+func TestExitCodeUnmappedType(t *testing.T) {
+	if got := ExitCode(New(Type(1000), "custom")); got != 1 {
+		t.Errorf("ExitCode() = %d, want 1", got)
+	}
+}
+
+func TestPrintExit(t *testing.T) {
+	var b strings.Builder
+	if code := printExit(&b, nil, false); code != 0 || b.Len() != 0 {
+		t.Errorf("printExit(nil) = %d, %q", code, b.String())
+	}
+
+	err := Wrap(TypeNotExist, errors.New("open app.yaml: no such file"), "load config")
+
+	b.Reset()
+	if code := printExit(&b, err, false); code != 66 {
+		t.Errorf("printExit() = %d, want 66", code)
+	}
+	want := "load config\n  ↳ open app.yaml: no such file\n"
+	if b.String() != want {
+		t.Errorf("output = %q, want %q", b.String(), want)
+	}
+
+	b.Reset()
+	printExit(&b, err, true)
+	if !strings.HasPrefix(b.String(), want[:len(want)-1]+"\n    at ") {
+		t.Errorf("verbose output = %q", b.String())
+	}
+	if !strings.Contains(b.String(), "TestPrintExit") {
+		t.Errorf("verbose output lacks the stack: %q", b.String())
+	}
+}
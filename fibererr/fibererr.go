@@ -0,0 +1,122 @@
+package fibererr
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/thamaji/terrors"
+	"github.com/thamaji/terrors/httperr"
+)
+
+type Option func(*options)
+
+type options struct {
+	logger func(c *fiber.Ctx, err error)
+	body   func(c *fiber.Ctx, err error, status int) interface{}
+}
+
+// WithLogger registers fn to be called for errors answered with a 5xx status
+// that are not marked handled. Log it with %+v to get the full chain and
+// stacks.
+func WithLogger(fn func(c *fiber.Ctx, err error)) Option {
+	return func(o *options) {
+		o.logger = fn
+	}
+}
+
+// WithWriter builds the default body with writer rather than
+// httperr.NewWriter().
+func WithWriter(writer *httperr.Writer) Option {
+	return func(o *options) {
+		o.body = func(c *fiber.Ctx, err error, status int) interface{} {
+			return writerBody(writer, c, err)
+		}
+	}
+}
+
+// WithBody replaces the default JSON body with the value returned by fn.
+func WithBody(fn func(c *fiber.Ctx, err error, status int) interface{}) Option {
+	return func(o *options) {
+		o.body = fn
+	}
+}
+
+// ErrorHandler returns a handler for fiber.Config.ErrorHandler. Nothing is
+// written when the handler already wrote a body.
+func ErrorHandler(opts ...Option) fiber.ErrorHandler {
+	o := &options{body: Body}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return func(c *fiber.Ctx, err error) error {
+		if err == nil {
+			return nil
+		}
+
+		status := Status(err)
+		if status >= http.StatusInternalServerError && o.logger != nil && !terrors.IsHandled(err) {
+			o.logger(c, err)
+		}
+
+		if len(c.Response().Body()) > 0 || c.Response().IsBodyStream() {
+			return nil
+		}
+
+		c.Status(status)
+		if c.Method() == http.MethodHead {
+			return nil
+		}
+		if werr := c.JSON(o.body(c, err, status)); werr != nil && o.logger != nil {
+			o.logger(c, werr)
+		}
+		return nil
+	}
+}
+
+func Status(err error) int {
+	if _, ok := terrors.TypeOk(err); !ok {
+		var fe *fiber.Error
+		if errors.As(err, &fe) {
+			return fe.Code
+		}
+	}
+	return terrors.HTTPStatus(err)
+}
+
+var defaultWriter = httperr.NewWriter()
+
+// Body is the default response body, built by httperr.NewWriter() as for
+// the HTTP handlers of this module: the type name, a message safe to show to
+// clients, the error and request ids, and a reference for 5xx responses. A
+// fiber.Error is typed from its code and keeps its message.
+func Body(c *fiber.Ctx, err error, status int) interface{} {
+	return writerBody(defaultWriter, c, err)
+}
+
+func writerBody(writer *httperr.Writer, c *fiber.Ctx, err error) interface{} {
+	var fe *fiber.Error
+	if _, typed := terrors.TypeOk(err); !typed && errors.As(err, &fe) {
+		err = terrors.WithStack(terrors.TypeOfHTTPStatus(fe.Code), err)
+	}
+
+	_, body := writer.Body(request(c), err)
+	return body
+}
+
+// request returns the method, path and headers of c for httperr, with the
+// request id set by the requestid middleware on the response when the
+// request has none.
+func request(c *fiber.Ctx) *http.Request {
+	r := &http.Request{Method: c.Method(), Header: http.Header{}}
+	c.Request().Header.VisitAll(func(k, v []byte) {
+		r.Header.Add(string(k), string(v))
+	})
+	if r.Header.Get(fiber.HeaderXRequestID) == "" {
+		if id := c.Response().Header.Peek(fiber.HeaderXRequestID); len(id) > 0 {
+			r.Header.Set(fiber.HeaderXRequestID, string(id))
+		}
+	}
+	return r
+}
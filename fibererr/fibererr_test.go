@@ -0,0 +1,91 @@
+package fibererr
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/thamaji/terrors"
+)
+
+// serve answers a GET of / with handler on an app using ErrorHandler(opts...).
+func serve(t *testing.T, handler fiber.Handler, opts ...Option) (int, map[string]interface{}) {
+	t.Helper()
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler(opts...)})
+	app.Use(recover.New())
+	app.Get("/", handler)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(fiber.HeaderXRequestID, "req-1")
+	resp, err := app.Test(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		t.Fatalf("body %q: %v", data, err)
+	}
+	return resp.StatusCode, body
+}
+
+func TestErrorHandler(t *testing.T) {
+	typed := terrors.Wrap(terrors.TypeNotExist, io.EOF, "user 1 not found")
+
+	tests := []struct {
+		name      string
+		handler   fiber.Handler
+		status    int
+		typ       string
+		message   string
+		reference bool
+	}{
+		{"typed", func(*fiber.Ctx) error { return typed }, 404, "not_exist", "user 1 not found: EOF", false},
+		{"fiber error", func(*fiber.Ctx) error { return fiber.NewError(http.StatusConflict, "busy") }, 409, "exist", "busy", false},
+		{"internal", func(*fiber.Ctx) error { return terrors.New(terrors.TypeInternal, "db password wrong") }, 500, "internal", "Internal Server Error", true},
+		{"panic", func(*fiber.Ctx) error { panic("nil map") }, 500, "unknown", "Internal Server Error", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logged []error
+			status, body := serve(t, tt.handler, WithLogger(func(c *fiber.Ctx, err error) { logged = append(logged, err) }))
+
+			if status != tt.status || body["type"] != tt.typ || body["message"] != tt.message {
+				t.Errorf("got %d %v, want %d %s %q", status, body, tt.status, tt.typ, tt.message)
+			}
+			if body["request_id"] != "req-1" {
+				t.Errorf("request_id = %v, want req-1", body["request_id"])
+			}
+			if _, ok := body["reference"]; ok != tt.reference {
+				t.Errorf("reference in %v = %v, want %v", body, ok, tt.reference)
+			}
+			if (len(logged) == 1) != (tt.status >= 500) {
+				t.Errorf("logged %v", logged)
+			}
+		})
+	}
+
+	if _, body := serve(t, func(*fiber.Ctx) error { return typed }); body["error_id"] != terrors.ID(typed) {
+		t.Errorf("error_id = %v, want %s", body["error_id"], terrors.ID(typed))
+	}
+}
+
+func TestErrorHandlerBody(t *testing.T) {
+	body := func(c *fiber.Ctx, err error, status int) interface{} {
+		return map[string]interface{}{"status": status}
+	}
+	if status, got := serve(t, func(*fiber.Ctx) error { return fiber.ErrTeapot }, WithBody(body)); status != http.StatusTeapot || got["status"] != float64(http.StatusTeapot) {
+		t.Errorf("got %d %v", status, got)
+	}
+}
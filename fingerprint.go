@@ -0,0 +1,34 @@
+package terrors
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+)
+
+// Fingerprint returns 16 hex digits identifying the kind of err rather
+// than the instance: the type and the innermost stack, or the message when
+// err has no stack. Errors created at the same place with different
+// arguments share a fingerprint. A nil error yields "".
+func Fingerprint(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(TypeOf(err).String()))
+
+	st := innermostStack(err)
+	if len(st) == 0 {
+		h.Write([]byte{0})
+		h.Write([]byte(err.Error()))
+	}
+	for _, f := range st {
+		function, _, line := frameInfo(f)
+		h.Write([]byte{0})
+		h.Write([]byte(function))
+		h.Write([]byte(strconv.Itoa(line)))
+	}
+
+	return fmt.Sprintf("%016x", h.Sum64())
+}
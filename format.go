@@ -0,0 +1,81 @@
+package terrors
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// formatVerbose writes the %+v representation of err: every layer of the
+// chain that contributes a message starts a new "caused by:" line and is
+// followed by the stacks it owns. Layers carrying only a stack lend it to the
+// next layer with a message, since that is the message they were created for.
+// Each stack is cut where it joins the next stack down the chain, so that a
+// layer shows only the frames between its creation and the layer below.
+// The instance ID follows when there is one, then the secondary errors, each
+// after "additionally:".
+func formatVerbose(w io.Writer, err error) {
+	stacks := AllStacks(err)
+	for i := 0; i+1 < len(stacks); i++ {
+		stacks[i] = trimShared(stacks[i], stacks[i+1])
+	}
+
+	var pending []errors.StackTrace
+	first := true
+
+	for e := err; e != nil; e = unwrapOnce(e) {
+		msg, split := ownMessage(e)
+		if _, ok := e.(StackTracer); ok {
+			pending = append(pending, stacks[0])
+			stacks = stacks[1:]
+		}
+		if msg == "" && split {
+			continue
+		}
+
+		if !first {
+			io.WriteString(w, "\ncaused by: ")
+		}
+		io.WriteString(w, msg)
+		first = false
+
+		for _, st := range pending {
+			formatStack(w, st)
+		}
+		pending = pending[:0]
+	}
+
+	for _, st := range pending {
+		formatStack(w, st)
+	}
+
+	if id := ID(err); id != "" {
+		io.WriteString(w, "\nerror id: "+id)
+	}
+
+	for _, e := range Secondary(err) {
+		fmt.Fprintf(w, "\nadditionally: %+v", e)
+	}
+}
+
+// trimShared drops the outermost frames st has in common with cause, keeping
+// at least the frame where st was captured.
+func trimShared(st errors.StackTrace, cause errors.StackTrace) errors.StackTrace {
+	i, j := len(st)-1, len(cause)-1
+	for i > 0 && j >= 0 && st[i] == cause[j] {
+		i--
+		j--
+	}
+	return st[:i+1]
+}
+
+func formatStack(w io.Writer, stack errors.StackTrace) {
+	stack, dropped := filterStack(stack)
+	for _, f := range stack {
+		fmt.Fprintf(w, "\n%+v", f)
+	}
+	if dropped > 0 {
+		io.WriteString(w, "\n"+externalFrames(dropped))
+	}
+}
@@ -0,0 +1,155 @@
+package terrors
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func formatOuter() error {
+	return Wrap(TypeInternal, formatMiddle(), "outer")
+}
+
+func formatMiddle() error {
+	return formatInner()
+}
+
+func formatInner() error {
+	return New(TypeNotExist, "inner")
+}
+
+// sections splits %+v output into its "caused by:" sections and returns the
+// functions of the frames listed in each.
+func sections(s string) [][]string {
+	var result [][]string
+	for _, section := range strings.Split(s, "\ncaused by: ") {
+		var functions []string
+		for _, line := range strings.Split(section, "\n")[1:] {
+			if strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "error id: ") || strings.HasPrefix(line, "… ") {
+				continue
+			}
+			functions = append(functions, line)
+		}
+		result = append(result, functions)
+	}
+	return result
+}
+
+func TestFormatVerboseTrimsSharedFrames(t *testing.T) {
+	appFrames(t)
+
+	got := sections(fmt.Sprintf("%+v", formatOuter()))
+	want := [][]string{
+		{"github.com/thamaji/terrors.formatOuter"},
+		{
+			"github.com/thamaji/terrors.formatInner",
+			"github.com/thamaji/terrors.formatMiddle",
+			"github.com/thamaji/terrors.formatOuter",
+			"github.com/thamaji/terrors.TestFormatVerboseTrimsSharedFrames",
+		},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("frames per section = %q, want %q", got, want)
+	}
+}
+
+func TestFormatVerboseKeepsStoredStacks(t *testing.T) {
+	err := formatOuter()
+
+	st, _ := StackTrace(err)
+	if len(st) < 2 || !strings.HasSuffix(fmt.Sprintf("%n", st[1]), "TestFormatVerboseKeepsStoredStacks") {
+		t.Errorf("stored stack was trimmed: %+v", st)
+	}
+}
+
+func TestFormatVerboseStackOnlyLayer(t *testing.T) {
+	appFrames(t)
+
+	err := WithStack(TypeInternal, formatInner())
+	got := sections(fmt.Sprintf("%+v", err))
+	want := [][]string{{
+		"github.com/thamaji/terrors.TestFormatVerboseStackOnlyLayer",
+		"github.com/thamaji/terrors.formatInner",
+		"github.com/thamaji/terrors.TestFormatVerboseStackOnlyLayer",
+	}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("frames per section = %q, want %q", got, want)
+	}
+}
+
+// The helpers below nest at known depths: depth0 wraps what depth1 returns,
+// depth1 wraps depth2 through depth1Helper, and depth3 creates the error.
+
+func depth0() error {
+	return Wrap(TypeInternal, depth1(), "depth0")
+}
+
+func depth1() error {
+	err := depth2()
+	return depth1Helper(err)
+}
+
+func depth1Helper(err error) error {
+	return Wrap(TypeUnavailable, err, "depth1")
+}
+
+func depth2() error {
+	return depth3()
+}
+
+func depth3() error {
+	return New(TypeNotExist, "depth3")
+}
+
+func TestFormatVerboseNesting(t *testing.T) {
+	appFrames(t)
+
+	err := depth0()
+	got := sections(fmt.Sprintf("%+v", err))
+	want := [][]string{
+		{"github.com/thamaji/terrors.depth0"},
+		{
+			"github.com/thamaji/terrors.depth1Helper",
+			"github.com/thamaji/terrors.depth1",
+		},
+		{
+			"github.com/thamaji/terrors.depth3",
+			"github.com/thamaji/terrors.depth2",
+			"github.com/thamaji/terrors.depth1",
+			"github.com/thamaji/terrors.depth0",
+			"github.com/thamaji/terrors.TestFormatVerboseNesting",
+		},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("frames per section = %q, want %q", got, want)
+	}
+
+	stacks := AllStacks(err)
+	if len(stacks) != 3 || len(stacks[0]) <= 1 || len(stacks[1]) <= 2 {
+		t.Errorf("stored stacks were trimmed: %v", stacks)
+	}
+}
+
+func TestFormatVerboseNestingForeignLayer(t *testing.T) {
+	appFrames(t)
+
+	err := Wrap(TypeInternal, fmt.Errorf("foreign: %w", depth1()), "outer")
+	got := sections(fmt.Sprintf("%+v", err))
+	want := [][]string{
+		{"github.com/thamaji/terrors.TestFormatVerboseNestingForeignLayer"},
+		{},
+		{
+			"github.com/thamaji/terrors.depth1Helper",
+			"github.com/thamaji/terrors.depth1",
+		},
+		{
+			"github.com/thamaji/terrors.depth3",
+			"github.com/thamaji/terrors.depth2",
+			"github.com/thamaji/terrors.depth1",
+			"github.com/thamaji/terrors.TestFormatVerboseNestingForeignLayer",
+		},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("frames per section = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,106 @@
+package ginterr
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thamaji/terrors"
+)
+
+const RequestIDHeader = "X-Request-Id"
+
+type Option func(*options)
+
+type options struct {
+	logger func(c *gin.Context, err error)
+}
+
+// WithLogger registers fn to be called with the error chosen for the
+// response, unless it is marked handled. Log it with %+v to get the full
+// chain and stacks.
+func WithLogger(fn func(c *gin.Context, err error)) Option {
+	return func(o *options) {
+		o.logger = fn
+	}
+}
+
+func Middleware(opts ...Option) gin.HandlerFunc {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return func(c *gin.Context) {
+		c.Next()
+
+		err := lastError(c)
+		if err == nil {
+			return
+		}
+
+		if o.logger != nil && !terrors.IsHandled(err) {
+			o.logger(c, err)
+		}
+
+		if c.Writer.Written() {
+			return
+		}
+		c.JSON(terrors.HTTPStatus(err), body(c, err))
+	}
+}
+
+func Abort(c *gin.Context, err error) {
+	if err == nil {
+		c.Abort()
+		return
+	}
+
+	_ = c.Error(err)
+	if c.Writer.Written() {
+		c.Abort()
+		return
+	}
+	c.AbortWithStatusJSON(terrors.HTTPStatus(err), body(c, err))
+}
+
+// lastError returns the last typed error recorded on c, or the last error of
+// any kind when none of them is typed.
+func lastError(c *gin.Context) error {
+	if len(c.Errors) == 0 {
+		return nil
+	}
+
+	for i := len(c.Errors) - 1; i >= 0; i-- {
+		if _, ok := terrors.TypeOk(c.Errors[i].Err); ok {
+			return c.Errors[i].Err
+		}
+	}
+	return c.Errors.Last().Err
+}
+
+func body(c *gin.Context, err error) gin.H {
+	status := terrors.HTTPStatus(err)
+
+	message := err.Error()
+	if status >= http.StatusInternalServerError {
+		message = http.StatusText(status)
+	}
+
+	h := gin.H{
+		"type":    terrors.TypeOf(err).String(),
+		"message": message,
+	}
+	if id := terrors.ID(err); id != "" {
+		h["error_id"] = id
+	}
+
+	requestID := c.GetHeader(RequestIDHeader)
+	if requestID == "" {
+		requestID = c.Writer.Header().Get(RequestIDHeader)
+	}
+	if requestID != "" {
+		h["request_id"] = requestID
+	}
+
+	return h
+}
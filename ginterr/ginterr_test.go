@@ -0,0 +1,106 @@
+package ginterr
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thamaji/terrors"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		body   map[string]interface{}
+	}{
+		{
+			name:   "typed",
+			err:    terrors.New(terrors.TypeNotExist, "user not found"),
+			status: http.StatusNotFound,
+			body:   map[string]interface{}{"type": "not_exist", "message": "user not found"},
+		},
+		{
+			name:   "plain",
+			err:    errors.New("db password is hunter2"),
+			status: http.StatusInternalServerError,
+			body:   map[string]interface{}{"type": "unknown", "message": "Internal Server Error"},
+		},
+		{
+			name:   "no error",
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logged error
+			r := gin.New()
+			r.Use(Middleware(WithLogger(func(c *gin.Context, err error) { logged = err })))
+			r.GET("/", func(c *gin.Context) {
+				if tt.err != nil {
+					_ = c.Error(tt.err)
+				}
+			})
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if logged != tt.err {
+				t.Errorf("logged %v, want %v", logged, tt.err)
+			}
+			if tt.body == nil {
+				if rec.Body.Len() != 0 {
+					t.Errorf("body = %q, want empty", rec.Body.String())
+				}
+				return
+			}
+
+			var body map[string]interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			delete(body, "error_id")
+			if len(body) != len(tt.body) {
+				t.Errorf("body = %v, want %v", body, tt.body)
+			}
+			for k, v := range tt.body {
+				if body[k] != v {
+					t.Errorf("body[%q] = %v, want %v", k, body[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestMiddlewareRequestID(t *testing.T) {
+	r := gin.New()
+	r.Use(Middleware())
+	r.GET("/", func(c *gin.Context) {
+		Abort(c, terrors.New(terrors.TypeInvalid, "bad input"))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusBadRequest || body["request_id"] != "req-1" {
+		t.Errorf("got %d %v", rec.Code, body)
+	}
+}
@@ -0,0 +1,5 @@
+module github.com/thamaji/terrors
+
+go 1.26.0
+
+require github.com/pkg/errors v0.9.1
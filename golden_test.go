@@ -0,0 +1,47 @@
+package terrors
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// golden compares got with testdata/name.golden, or rewrites the file under
+// -update. The name of the checkout directory is replaced with "terrors" so
+// that trimmed paths do not depend on where the repository lives.
+func golden(t *testing.T, name string, got string) {
+	t.Helper()
+
+	if wd, err := os.Getwd(); err == nil {
+		got = strings.ReplaceAll(got, filepath.Base(wd)+"/", "terrors/")
+	}
+
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("%s mismatch:\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+// appFrames limits rendered stacks to this package for the duration of t, so
+// that golden output does not depend on the testing and runtime frames.
+func appFrames(t *testing.T) {
+	t.Helper()
+
+	OnlyAppFrames("github.com/thamaji/terrors")
+	t.Cleanup(AllFrames)
+}
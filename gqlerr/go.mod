@@ -0,0 +1,18 @@
+module github.com/thamaji/terrors/gqlerr
+
+go 1.26.0
+
+require (
+	github.com/99designs/gqlgen v0.17.95
+	github.com/thamaji/terrors v0.0.0
+	github.com/vektah/gqlparser/v2 v2.5.58
+)
+
+require (
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/sosodev/duration v1.4.0 // indirect
+	golang.org/x/sync v0.22.0 // indirect
+)
+
+replace github.com/thamaji/terrors => ../
@@ -0,0 +1,99 @@
+package gqlerr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/thamaji/terrors"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+type Option func(*options)
+
+type options struct {
+	logger func(ctx context.Context, err error)
+}
+
+// WithLogger registers fn to be called with the resolver error whenever its
+// details are hidden from the client and it is not marked handled. Log it
+// with %+v to get the full chain.
+func WithLogger(fn func(ctx context.Context, err error)) Option {
+	return func(o *options) {
+		o.logger = fn
+	}
+}
+
+// ErrorPresenter adds the code and the type of resolver errors to their
+// extensions and hides the message of the errors that map to a 5xx status,
+// see terrors.HTTPStatus.
+func ErrorPresenter(opts ...Option) graphql.ErrorPresenterFunc {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return func(ctx context.Context, err error) *gqlerror.Error {
+		gerr := graphql.DefaultErrorPresenter(ctx, err)
+
+		cause := err
+		var ge *gqlerror.Error
+		if errors.As(err, &ge) {
+			if ge.Err == nil {
+				return gerr
+			}
+			cause = ge.Err
+		}
+
+		t := terrors.TypeOf(cause)
+		if gerr.Extensions == nil {
+			gerr.Extensions = map[string]interface{}{}
+		}
+		gerr.Extensions["code"] = Code(t)
+		gerr.Extensions["type"] = t.String()
+
+		if terrors.HTTPStatus(cause) >= http.StatusInternalServerError {
+			if o.logger != nil && !terrors.IsHandled(cause) {
+				o.logger(ctx, cause)
+			}
+			gerr.Message = "internal server error"
+		}
+
+		return gerr
+	}
+}
+
+// RecoverFunc converts panics of resolvers to TypeInternal errors whose
+// stack starts at the panic, see terrors.FromPanic.
+func RecoverFunc() graphql.RecoverFunc {
+	return func(ctx context.Context, p interface{}) error {
+		return terrors.FromPanic(p)
+	}
+}
+
+func Code(t terrors.Type) string {
+	switch t {
+	case terrors.TypeInvalid:
+		return "BAD_USER_INPUT"
+	case terrors.TypeUnauthorized:
+		return "UNAUTHENTICATED"
+	case terrors.TypePermission:
+		return "FORBIDDEN"
+	case terrors.TypeNotExist:
+		return "NOT_FOUND"
+	case terrors.TypeExist:
+		return "ALREADY_EXISTS"
+	case terrors.TypeInternal:
+		return "INTERNAL_SERVER_ERROR"
+	case terrors.TypeConflict:
+		return "CONFLICT"
+	case terrors.TypeCanceled:
+		return "CANCELED"
+	case terrors.TypeTimeout:
+		return "TIMEOUT"
+	case terrors.TypeUnavailable:
+		return "UNAVAILABLE"
+	}
+	return "UNKNOWN"
+}
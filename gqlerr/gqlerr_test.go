@@ -0,0 +1,96 @@
+package gqlerr
+
+import (
+	"context"
+	stderrors "errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/thamaji/terrors"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+func TestErrorPresenter(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		message string
+		code    string
+		typ     string
+		logged  bool
+	}{
+		{"typed 4xx", terrors.New(terrors.TypeNotExist, "user 42 not found"), "user 42 not found", "NOT_FOUND", "not_exist", false},
+		{"internal", terrors.Wrap(terrors.TypeInternal, io.EOF, "read config"), "internal server error", "INTERNAL_SERVER_ERROR", "internal", true},
+		{"untyped", stderrors.New("pq: password authentication failed"), "internal server error", "UNKNOWN", "unknown", true},
+		{"unavailable", terrors.New(terrors.TypeUnavailable, "db at 10.0.0.3 down"), "internal server error", "UNAVAILABLE", "unavailable", true},
+		{"joined", stderrors.Join(terrors.New(terrors.TypeInvalid, "bad name"), terrors.New(terrors.TypeInternal, "disk full")), "internal server error", "INTERNAL_SERVER_ERROR", "internal", true},
+		{"wrapped in gqlerror", &gqlerror.Error{Message: "x", Err: terrors.New(terrors.TypeInvalid, "bad name")}, "x", "BAD_USER_INPUT", "invalid", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logged error
+			present := ErrorPresenter(WithLogger(func(ctx context.Context, err error) { logged = err }))
+
+			gerr := present(context.Background(), tt.err)
+			if gerr.Message != tt.message {
+				t.Errorf("Message = %q, want %q", gerr.Message, tt.message)
+			}
+			if gerr.Extensions["code"] != tt.code || gerr.Extensions["type"] != tt.typ {
+				t.Errorf("Extensions = %v, want code %s and type %s", gerr.Extensions, tt.code, tt.typ)
+			}
+			if (logged != nil) != tt.logged {
+				t.Errorf("logged %v, want logged %v", logged, tt.logged)
+			}
+		})
+	}
+}
+
+func TestErrorPresenterHandled(t *testing.T) {
+	called := false
+	present := ErrorPresenter(WithLogger(func(ctx context.Context, err error) { called = true }))
+
+	gerr := present(context.Background(), terrors.MarkHandled(terrors.New(terrors.TypeInternal, "disk full")))
+	if called || gerr.Message != "internal server error" {
+		t.Errorf("Message = %q, logged %v", gerr.Message, called)
+	}
+}
+
+func TestErrorPresenterPlainGQLError(t *testing.T) {
+	in := &gqlerror.Error{Message: "syntax error"}
+	if gerr := ErrorPresenter()(context.Background(), in); gerr.Message != "syntax error" || gerr.Extensions != nil {
+		t.Errorf("ErrorPresenter() = %q %v, want it unchanged", gerr.Message, gerr.Extensions)
+	}
+}
+
+func resolverPanics() {
+	panic(io.ErrUnexpectedEOF)
+}
+
+func TestRecoverFunc(t *testing.T) {
+	var err error
+	func() {
+		defer func() {
+			err = RecoverFunc()(context.Background(), recover())
+		}()
+		resolverPanics()
+	}()
+
+	if terrors.TypeOf(err) != terrors.TypeInternal || !stderrors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("RecoverFunc() = %v (%v)", err, terrors.TypeOf(err))
+	}
+	if function, _, _, ok := terrors.Caller(err); !ok || !strings.HasSuffix(function, ".resolverPanics") {
+		t.Errorf("Caller() = %s, want the panicking resolver", function)
+	}
+
+	func() {
+		defer func() {
+			err = RecoverFunc()(context.Background(), recover())
+		}()
+		panic("nil map")
+	}()
+	if terrors.TypeOf(err) != terrors.TypeInternal || err.Error() != "panic: nil map" {
+		t.Errorf("RecoverFunc() = %v (%v)", err, terrors.TypeOf(err))
+	}
+}
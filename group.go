@@ -0,0 +1,122 @@
+package terrors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+type GroupOption func(*Group)
+
+// CollectAll makes Wait return all the errors of the group joined, in the
+// order they occurred, instead of only the first one.
+func CollectAll() GroupOption {
+	return func(g *Group) {
+		g.collectAll = true
+	}
+}
+
+// CancelOnError cancels the context of the group as soon as a task fails.
+func CancelOnError() GroupOption {
+	return func(g *Group) {
+		g.cancelOnError = true
+	}
+}
+
+// Group runs tasks in goroutines and collects their errors. A panicking task
+// fails with a TypeInternal error whose stack starts at the panic.
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu   sync.Mutex
+	errs []error
+
+	collectAll    bool
+	cancelOnError bool
+}
+
+func NewGroup(ctx context.Context, opts ...GroupOption) *Group {
+	ctx, cancel := context.WithCancel(ctx)
+	g := &Group{ctx: ctx, cancel: cancel}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+func (g *Group) Go(fn func(ctx context.Context) error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+
+		err := g.run(fn)
+		if err == nil {
+			return
+		}
+
+		g.mu.Lock()
+		if g.collectAll || len(g.errs) == 0 {
+			g.errs = append(g.errs, err)
+		}
+		g.mu.Unlock()
+
+		if g.cancelOnError {
+			g.cancel()
+		}
+	}()
+}
+
+func (g *Group) run(fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = FromPanic(p)
+		}
+	}()
+	return fn(g.ctx)
+}
+
+// Wait waits for all the tasks and returns the first error that occurred, or
+// with CollectAll all of them joined. TypeOf of a joined result follows the
+// type priority, see SetTypePriority.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	switch {
+	case len(g.errs) == 0:
+		return nil
+	case len(g.errs) == 1 || !g.collectAll:
+		return g.errs[0]
+	}
+	return stderrors.Join(g.errs...)
+}
+
+// FromPanic converts a value recovered from a panic to a TypeInternal error
+// whose stack starts at the panic. It must be called while the panic is
+// recovered, from the deferred function that called recover or a function
+// it calls, such as the recover hook of a framework; elsewhere the stack
+// starts at its caller.
+func FromPanic(p interface{}) error {
+	stack := errors.New("").(StackTracer).StackTrace()
+	for i, f := range stack {
+		if function, _, _ := frameInfo(f); strings.HasPrefix(function, "runtime.gopanic") {
+			stack = stack[i:]
+			break
+		}
+	}
+	stack = stack[1:]
+
+	if err, ok := p.(error); ok {
+		return newWrapped(TypeInternal, err, "panic", stack)
+	}
+	return newFundamental(TypeInternal, fmt.Sprintf("panic: %v", p), stack)
+}
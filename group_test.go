@@ -0,0 +1,100 @@
+package terrors
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func panicking(i int) error {
+	if i%2 == 0 {
+		panic(fmt.Sprintf("task %d", i))
+	}
+	panic(Errorf(TypeNotExist, "task %d", i))
+}
+
+func TestGroupConcurrent(t *testing.T) {
+	const n = 200
+
+	g := NewGroup(context.Background(), CollectAll())
+	var succeeded atomic.Int64
+	for i := 0; i < n; i++ {
+		i := i
+		g.Go(func(ctx context.Context) error {
+			switch i % 4 {
+			case 0:
+				succeeded.Add(1)
+				return nil
+			case 1:
+				return Wrapf(TypeUnavailable, io.EOF, "task %d", i)
+			default:
+				return panicking(i)
+			}
+		})
+	}
+
+	err := g.Wait()
+	errs := err.(interface{ Unwrap() []error }).Unwrap()
+	if len(errs) != n-n/4 || succeeded.Load() != n/4 {
+		t.Fatalf("%d errors and %d successes, want %d and %d", len(errs), succeeded.Load(), n-n/4, n/4)
+	}
+
+	panics := 0
+	for _, e := range errs {
+		switch TypeOf(e) {
+		case TypeUnavailable:
+		case TypeInternal:
+			panics++
+			if !strings.HasPrefix(e.Error(), "panic") {
+				t.Errorf("panic error %q", e)
+			}
+			if st, _ := StackTrace(e); !strings.HasPrefix(fmt.Sprintf("%n", st[0]), "panicking") {
+				t.Errorf("panic stack starts at %n, want panicking", st[0])
+			}
+		default:
+			t.Errorf("unexpected error %v (%v)", e, TypeOf(e))
+		}
+	}
+	if panics != n/2 {
+		t.Errorf("%d panics, want %d", panics, n/2)
+	}
+	if !HasType(err, TypeNotExist) {
+		t.Error("panicking with an error lost its type")
+	}
+}
+
+func TestGroupFirstError(t *testing.T) {
+	g := NewGroup(context.Background(), CancelOnError())
+	first := make(chan struct{})
+	g.Go(func(ctx context.Context) error {
+		defer close(first)
+		return New(TypeInvalid, "first")
+	})
+	for i := 0; i < 50; i++ {
+		g.Go(func(ctx context.Context) error {
+			<-first
+			<-ctx.Done()
+			return Wrap(TypeCanceled, ctx.Err(), "canceled")
+		})
+	}
+
+	if err := g.Wait(); err == nil || err.Error() != "first" {
+		t.Errorf("Wait() = %v, want the first error", err)
+	}
+}
+
+func TestGroupNoError(t *testing.T) {
+	g := NewGroup(context.Background())
+	for i := 0; i < 10; i++ {
+		g.Go(func(ctx context.Context) error { return nil })
+	}
+	if err := g.Wait(); err != nil {
+		t.Errorf("Wait() = %v", err)
+	}
+	if err := NewGroup(context.Background()).Wait(); err != nil {
+		t.Errorf("Wait() of an empty group = %v", err)
+	}
+}
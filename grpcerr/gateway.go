@@ -0,0 +1,31 @@
+package grpcerr
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/thamaji/terrors/httperr"
+	"google.golang.org/grpc/status"
+)
+
+// GatewayErrorHandler returns a grpc-gateway error handler writing the
+// errors restored from the details attached by ToGRPCStatus with writer,
+// the same body as the HTTP handlers of the service. Other errors are left
+// to runtime.DefaultHTTPErrorHandler. A nil writer means
+// httperr.NewWriter().
+func GatewayErrorHandler(writer *httperr.Writer) runtime.ErrorHandlerFunc {
+	if writer == nil {
+		writer = httperr.NewWriter()
+	}
+
+	return func(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+		if st, ok := status.FromError(err); ok {
+			if restored, ok := fromDetails(st); ok {
+				writer.Write(w, r, restored)
+				return
+			}
+		}
+		runtime.DefaultHTTPErrorHandler(ctx, mux, marshaler, w, r, err)
+	}
+}
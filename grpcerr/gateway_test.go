@@ -0,0 +1,88 @@
+package grpcerr
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/thamaji/terrors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// gateway answers err with the handler of GatewayErrorHandler(nil).
+func gateway(t *testing.T, err error) (*httptest.ResponseRecorder, map[string]interface{}) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/v1/users/1", nil)
+	GatewayErrorHandler(nil)(context.Background(), runtime.NewServeMux(), &runtime.JSONPb{}, rec, r, err)
+
+	var body map[string]interface{}
+	if jerr := json.Unmarshal(rec.Body.Bytes(), &body); jerr != nil {
+		t.Fatalf("body %q: %v", rec.Body, jerr)
+	}
+	return rec, body
+}
+
+// withChain builds by hand the status a server sends for chain.
+func withChain(t *testing.T, code codes.Code, domain string, chain string) error {
+	t.Helper()
+
+	st, err := status.New(code, "ignored").WithDetails(&errdetails.ErrorInfo{
+		Reason:   "NOT_EXIST",
+		Domain:   domain,
+		Metadata: map[string]string{"chain": chain},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return received(t, st)
+}
+
+func TestGatewayErrorHandler(t *testing.T) {
+	chain := terrors.WithMessage(terrors.TypeNotExist, terrors.New(terrors.TypeInternal, "no row"), "user 1 not found")
+	err := withChain(t, codes.Unknown, Domain, base64.StdEncoding.EncodeToString(terrors.AppendBinary(nil, chain)))
+
+	rec, body := gateway(t, err)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+	if body["type"] != "not_exist" || body["message"] != "user 1 not found: no row" {
+		t.Errorf("body = %v", body)
+	}
+	if _, ok := body["code"]; ok {
+		t.Errorf("body = %v, want the body of httperr", body)
+	}
+}
+
+func TestGatewayErrorHandlerFallback(t *testing.T) {
+	valid := base64.StdEncoding.EncodeToString(terrors.AppendBinary(nil, terrors.New(terrors.TypeNotExist, "missing")))
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"no details", status.Error(codes.Unavailable, "backend down")},
+		{"other domain", withChain(t, codes.Unavailable, "example.com", valid)},
+		{"malformed chain", withChain(t, codes.Unavailable, Domain, "%%%")},
+		{"undecodable chain", withChain(t, codes.Unavailable, Domain, base64.StdEncoding.EncodeToString([]byte{0xff}))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, body := gateway(t, tt.err)
+			if rec.Code != http.StatusServiceUnavailable {
+				t.Errorf("status = %d, want 503", rec.Code)
+			}
+			if body["code"] != float64(codes.Unavailable) {
+				t.Errorf("body = %v, want the body of runtime.DefaultHTTPErrorHandler", body)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package grpcerr
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"github.com/thamaji/terrors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Domain is the ErrorInfo domain of the details attached by ToGRPCStatus.
+const Domain = "github.com/thamaji/terrors"
+
+// ToGRPCStatus converts err to a status whose code is mapped from its type.
+// The status carries an ErrorInfo detail holding the type and the encoded
+// chain so that FromGRPCError can restore it on the other side.
+func ToGRPCStatus(err error) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+	if _, ok := terrors.TypeOk(err); !ok {
+		if st, ok := status.FromError(err); ok {
+			return st
+		}
+	}
+
+	t := terrors.TypeOf(err)
+	st := status.New(Code(t), err.Error())
+	withDetails, derr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: strings.ToUpper(t.String()),
+		Domain: Domain,
+		Metadata: map[string]string{
+			"chain": base64.StdEncoding.EncodeToString(terrors.AppendBinary(nil, err)),
+		},
+	})
+	if derr != nil {
+		return st
+	}
+	return withDetails
+}
+
+func ToGRPCError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return ToGRPCStatus(err).Err()
+}
+
+// FromGRPCError restores the typed error carried by the status of err, or
+// types err from its status code when the peer attached no ErrorInfo from
+// this package. Errors without a status are returned unchanged.
+func FromGRPCError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	if st.Code() == codes.OK {
+		return nil
+	}
+
+	if restored, ok := fromDetails(st); ok {
+		return restored
+	}
+	return terrors.WithStack(TypeOfCode(st.Code()), err)
+}
+
+func fromDetails(st *status.Status) (error, bool) {
+	for _, d := range st.Details() {
+		info, ok := d.(*errdetails.ErrorInfo)
+		if !ok || info.GetDomain() != Domain {
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(info.GetMetadata()["chain"])
+		if err != nil {
+			continue
+		}
+		restored, err := terrors.DecodeBinary(data)
+		if err != nil || restored == nil {
+			continue
+		}
+		return restored, true
+	}
+	return nil, false
+}
+
+func Code(t terrors.Type) codes.Code {
+	switch t {
+	case terrors.TypeNotError:
+		return codes.OK
+	case terrors.TypeInvalid:
+		return codes.InvalidArgument
+	case terrors.TypePermission:
+		return codes.PermissionDenied
+	case terrors.TypeExist:
+		return codes.AlreadyExists
+	case terrors.TypeNotExist:
+		return codes.NotFound
+	case terrors.TypeInternal:
+		return codes.Internal
+	case terrors.TypeUnauthorized:
+		return codes.Unauthenticated
+	case terrors.TypeConflict:
+		return codes.Aborted
+	case terrors.TypeCanceled:
+		return codes.Canceled
+	case terrors.TypeTimeout:
+		return codes.DeadlineExceeded
+	case terrors.TypeUnavailable:
+		return codes.Unavailable
+	}
+	return codes.Unknown
+}
+
+func TypeOfCode(code codes.Code) terrors.Type {
+	switch code {
+	case codes.OK:
+		return terrors.TypeNotError
+	case codes.InvalidArgument, codes.OutOfRange, codes.FailedPrecondition:
+		return terrors.TypeInvalid
+	case codes.PermissionDenied:
+		return terrors.TypePermission
+	case codes.AlreadyExists:
+		return terrors.TypeExist
+	case codes.NotFound:
+		return terrors.TypeNotExist
+	case codes.Internal, codes.DataLoss, codes.Unimplemented:
+		return terrors.TypeInternal
+	case codes.Unauthenticated:
+		return terrors.TypeUnauthorized
+	case codes.Aborted:
+		return terrors.TypeConflict
+	case codes.Canceled:
+		return terrors.TypeCanceled
+	case codes.DeadlineExceeded:
+		return terrors.TypeTimeout
+	case codes.Unavailable, codes.ResourceExhausted:
+		return terrors.TypeUnavailable
+	}
+	return terrors.TypeUnknown
+}
@@ -0,0 +1,117 @@
+package grpcerr
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/thamaji/terrors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// roundTrip sends err through the wire encoding of its status, the way a
+// server and a client would.
+func roundTrip(t *testing.T, err error) error {
+	t.Helper()
+
+	st, ok := status.FromError(ToGRPCError(err))
+	if !ok {
+		t.Fatalf("ToGRPCError(%v) has no status", err)
+	}
+	return FromGRPCError(received(t, st))
+}
+
+func received(t *testing.T, st *status.Status) error {
+	t.Helper()
+
+	b, err := proto.Marshal(st.Proto())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p spb.Status
+	if err := proto.Unmarshal(b, &p); err != nil {
+		t.Fatal(err)
+	}
+	return status.ErrorProto(&p)
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{"new", terrors.New(terrors.TypeNotExist, "user not found"), codes.NotFound},
+		{"wrapped", terrors.Wrap(terrors.TypeUnavailable, fmt.Errorf("dial: %w", io.EOF), "connect"), codes.Unavailable},
+		{"chain", terrors.WithMessage(terrors.TypePermission, terrors.New(terrors.TypeUnauthorized, "token expired"), "list"), codes.PermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ToGRPCStatus(tt.err).Code(); got != tt.code {
+				t.Errorf("Code() = %v, want %v", got, tt.code)
+			}
+
+			got := roundTrip(t, tt.err)
+			if got.Error() != tt.err.Error() {
+				t.Errorf("Error() = %q, want %q", got.Error(), tt.err.Error())
+			}
+			if terrors.TypeOf(got) != terrors.TypeOf(tt.err) || terrors.RootType(got) != terrors.RootType(tt.err) {
+				t.Errorf("types = %v/%v, want %v/%v", terrors.TypeOf(got), terrors.RootType(got), terrors.TypeOf(tt.err), terrors.RootType(tt.err))
+			}
+		})
+	}
+}
+
+func TestFromGRPCErrorWithoutDetails(t *testing.T) {
+	peer := status.New(codes.DeadlineExceeded, "upstream timed out")
+
+	got := FromGRPCError(received(t, peer))
+	if terrors.TypeOf(got) != terrors.TypeTimeout {
+		t.Errorf("TypeOf() = %v, want timeout", terrors.TypeOf(got))
+	}
+	if st, ok := status.FromError(terrors.Cause(got)); !ok || st.Message() != "upstream timed out" || st.Code() != codes.DeadlineExceeded {
+		t.Errorf("status of the cause = %v, %v", st, ok)
+	}
+}
+
+func TestFromGRPCErrorForeignDetails(t *testing.T) {
+	peer, err := status.New(codes.NotFound, "no such key").WithDetails(&errdetails.ErrorInfo{
+		Reason: "NOT_FOUND", Domain: "example.com", Metadata: map[string]string{"chain": "AAAA"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := FromGRPCError(received(t, peer)); terrors.TypeOf(got) != terrors.TypeNotExist || got.Error() != "rpc error: code = NotFound desc = no such key" {
+		t.Errorf("FromGRPCError() = %v (%v)", got, terrors.TypeOf(got))
+	}
+}
+
+func TestFromGRPCErrorEdges(t *testing.T) {
+	if FromGRPCError(nil) != nil || FromGRPCError(status.Error(codes.OK, "")) != nil {
+		t.Error("FromGRPCError() of no error is not nil")
+	}
+	if plain := io.EOF; FromGRPCError(plain) != plain {
+		t.Error("FromGRPCError() changed an error without a status")
+	}
+	if st := ToGRPCStatus(status.Error(codes.Aborted, "retry")); st.Code() != codes.Aborted {
+		t.Errorf("ToGRPCStatus() of a status error = %v", st.Code())
+	}
+}
+
+func TestRoundTripInvariant(t *testing.T) {
+	err := terrors.Wrap(terrors.TypeInternal, terrors.Assert(false, "cache is empty"), "lookup")
+
+	got := roundTrip(t, err)
+	if !terrors.IsInvariant(got) {
+		t.Error("IsInvariant() = false after the round trip")
+	}
+	if got.Error() != err.Error() {
+		t.Errorf("Error() = %q, want %q", got.Error(), err.Error())
+	}
+}
@@ -0,0 +1,56 @@
+package terrors
+
+import (
+	"fmt"
+	"io"
+)
+
+// MarkHandled flags err as already logged or otherwise dealt with, without
+// changing its message, type or stacks. The flag stays visible through
+// further wrapping; see IsHandled.
+func MarkHandled(err error) error {
+	if err == nil || IsHandled(err) {
+		return err
+	}
+	return &withHandled{cause: err}
+}
+
+func IsHandled(err error) bool {
+	handled := false
+	walk(err, func(e error) bool {
+		_, handled = e.(*withHandled)
+		return !handled
+	})
+	return handled
+}
+
+type withHandled struct {
+	cause error
+}
+
+func (w *withHandled) Error() string {
+	return w.cause.Error()
+}
+
+func (w *withHandled) Cause() error {
+	return w.cause
+}
+
+func (w *withHandled) Unwrap() error {
+	return w.cause
+}
+
+func (w *withHandled) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			formatVerbose(s, w)
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, w.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", w.Error())
+	}
+}
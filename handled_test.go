@@ -0,0 +1,59 @@
+package terrors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestMarkHandled(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"mark then wrap", Wrap(TypeInternal, MarkHandled(New(TypeNotExist, "missing")), "load")},
+		{"mark then foreign wrap", fmt.Errorf("serve: %w", MarkHandled(New(TypeNotExist, "missing")))},
+		{"wrap then mark", MarkHandled(Wrap(TypeInternal, New(TypeNotExist, "missing"), "load"))},
+		{"mark in join", stderrors.Join(io.EOF, MarkHandled(New(TypeNotExist, "missing")))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !IsHandled(tt.err) {
+				t.Error("IsHandled() = false")
+			}
+			if !HasType(tt.err, TypeNotExist) || tt.err.Error() == "" {
+				t.Errorf("marking lost the error: %q", tt.err)
+			}
+		})
+	}
+}
+
+func TestMarkHandledKeepsTheError(t *testing.T) {
+	orig := Wrap(TypeInternal, New(TypeNotExist, "missing"), "load")
+	marked := MarkHandled(orig)
+
+	if marked.Error() != orig.Error() || TypeOf(marked) != TypeOf(orig) || ID(marked) != ID(orig) {
+		t.Errorf("MarkHandled changed the error: %q %v", marked, TypeOf(marked))
+	}
+	if fmt.Sprint(AllStacks(marked)) != fmt.Sprint(AllStacks(orig)) {
+		t.Error("MarkHandled changed the stacks")
+	}
+	if IsHandled(orig) {
+		t.Error("MarkHandled changed the original")
+	}
+}
+
+func TestMarkHandledTwice(t *testing.T) {
+	once := MarkHandled(io.EOF)
+	if twice := MarkHandled(once); twice != once {
+		t.Errorf("MarkHandled() of a handled error = %#v, want it unchanged", twice)
+	}
+	if wrapped := Wrap(TypeInternal, once, "read"); MarkHandled(wrapped) != wrapped {
+		t.Error("MarkHandled() of a wrapped handled error added a layer")
+	}
+	if MarkHandled(nil) != nil || IsHandled(nil) || IsHandled(io.EOF) {
+		t.Error("nil and unmarked errors are handled")
+	}
+}
@@ -0,0 +1,104 @@
+package terrors
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+func HTTPStatus(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	return HTTPStatusOf(TypeOf(err))
+}
+
+var (
+	httpStatusesMu sync.RWMutex
+	httpStatuses   = map[Type]int{}
+)
+
+// SetHTTPStatus overrides the status HTTPStatus uses for errors of type t.
+// Statuses outside 100-599 are rejected.
+func SetHTTPStatus(t Type, status int) error {
+	if status < 100 || status > 599 {
+		return Errorf(TypeInvalid, "terrors: invalid HTTP status %d", status)
+	}
+
+	httpStatusesMu.Lock()
+	httpStatuses[t] = status
+	httpStatusesMu.Unlock()
+	return nil
+}
+
+func HTTPStatusOf(t Type) int {
+	httpStatusesMu.RLock()
+	status, ok := httpStatuses[t]
+	httpStatusesMu.RUnlock()
+	if ok {
+		return status
+	}
+
+	switch t {
+	case TypeNotError:
+		return http.StatusOK
+	case TypeInvalid:
+		return http.StatusBadRequest
+	case TypeUnauthorized:
+		return http.StatusUnauthorized
+	case TypePermission:
+		return http.StatusForbidden
+	case TypeNotExist:
+		return http.StatusNotFound
+	case TypeExist, TypeConflict:
+		return http.StatusConflict
+	case TypeCanceled:
+		return 499 // client closed request
+	case TypeTimeout:
+		return http.StatusGatewayTimeout
+	case TypeUnavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// TypeOfHTTPStatus is the inverse of HTTPStatus for the common error
+// statuses.
+func TypeOfHTTPStatus(status int) Type {
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return TypeInvalid
+	case http.StatusUnauthorized:
+		return TypeUnauthorized
+	case http.StatusForbidden:
+		return TypePermission
+	case http.StatusNotFound, http.StatusGone:
+		return TypeNotExist
+	case http.StatusConflict:
+		return TypeExist
+	case 499:
+		return TypeCanceled
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return TypeTimeout
+	case http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusBadGateway:
+		return TypeUnavailable
+	}
+	if status >= http.StatusInternalServerError {
+		return TypeInternal
+	}
+	if status < http.StatusBadRequest {
+		return TypeNotError
+	}
+	return TypeUnknown
+}
+
+// FromHTTPStatus creates an error typed from an HTTP status, with msg as its
+// message or the status text when msg is empty.
+func FromHTTPStatus(status int, msg string) error {
+	if msg == "" {
+		msg = http.StatusText(status)
+	}
+	stack := errors.New(msg).(StackTracer).StackTrace()
+	return newFundamental(TypeOfHTTPStatus(status), msg, stack[1:])
+}
@@ -0,0 +1,106 @@
+package terrors
+
+import (
+	"fmt"
+	"io"
+	"testing"
+)
+
+// resetHTTPStatus removes the override of t for the rest of the test.
+func resetHTTPStatus(t *testing.T, typ Type) {
+	t.Helper()
+
+	t.Cleanup(func() {
+		httpStatusesMu.Lock()
+		delete(httpStatuses, typ)
+		httpStatusesMu.Unlock()
+	})
+}
+
+func TestHTTPStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, 200},
+		{io.EOF, 500},
+		{New(TypeInvalid, "bad"), 400},
+		{New(TypeExist, "dup"), 409},
+		{New(TypeCanceled, "gone"), 499},
+		{Wrap(TypeTimeout, New(TypeNotExist, "missing"), "slow"), 504},
+	}
+
+	for _, tt := range tests {
+		if got := HTTPStatus(tt.err); got != tt.want {
+			t.Errorf("HTTPStatus(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestSetHTTPStatus(t *testing.T) {
+	resetHTTPStatus(t, TypeConflict)
+
+	if err := SetHTTPStatus(TypeConflict, 423); err != nil {
+		t.Fatal(err)
+	}
+	if got := HTTPStatus(New(TypeConflict, "locked")); got != 423 {
+		t.Errorf("overridden status = %d, want 423", got)
+	}
+	if got := HTTPStatus(New(TypeExist, "dup")); got != 409 {
+		t.Errorf("status of a type sharing the default = %d, want 409", got)
+	}
+	if got := HTTPStatus(Wrap(TypeInvalid, New(TypeConflict, "locked"), "save")); got != 400 {
+		t.Errorf("status follows the outer type: %d, want 400", got)
+	}
+
+	if err := SetHTTPStatus(TypeConflict, 429); err != nil {
+		t.Fatal(err)
+	}
+	if got := HTTPStatusOf(TypeConflict); got != 429 {
+		t.Errorf("last override wins: %d, want 429", got)
+	}
+}
+
+func TestSetHTTPStatusRegisteredType(t *testing.T) {
+	quota, err := RegisterType("test_http_quota")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resetHTTPStatus(t, quota)
+
+	if got := HTTPStatus(New(quota, "over quota")); got != 500 {
+		t.Errorf("status of a registered type = %d, want 500 by default", got)
+	}
+	if err := SetHTTPStatus(quota, 429); err != nil {
+		t.Fatal(err)
+	}
+	if got := HTTPStatus(fmt.Errorf("call: %w", New(quota, "over quota"))); got != 429 {
+		t.Errorf("status of a registered type = %d, want 429", got)
+	}
+}
+
+func TestSetHTTPStatusRejected(t *testing.T) {
+	for _, status := range []int{-1, 0, 99, 600, 1000} {
+		if err := SetHTTPStatus(TypeInvalid, status); TypeOf(err) != TypeInvalid {
+			t.Errorf("SetHTTPStatus(%d) = %v, want a TypeInvalid error", status, err)
+		}
+	}
+	if got := HTTPStatusOf(TypeInvalid); got != 400 {
+		t.Errorf("a rejected status was stored: %d", got)
+	}
+}
+
+func TestTypeOfHTTPStatus(t *testing.T) {
+	for _, tt := range []struct {
+		status int
+		want   Type
+	}{
+		{200, TypeNotError}, {302, TypeNotError}, {400, TypeInvalid}, {401, TypeUnauthorized},
+		{403, TypePermission}, {404, TypeNotExist}, {409, TypeExist}, {418, TypeUnknown},
+		{429, TypeUnavailable}, {499, TypeCanceled}, {501, TypeInternal}, {504, TypeTimeout},
+	} {
+		if got := TypeOfHTTPStatus(tt.status); got != tt.want {
+			t.Errorf("TypeOfHTTPStatus(%d) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
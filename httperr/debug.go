@@ -0,0 +1,74 @@
+package httperr
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/thamaji/terrors"
+)
+
+var debugResponses atomic.Bool
+
+// SetDebugResponses makes every Writer add the layers of the error chain to
+// the body, under "debug": one object per layer with its type, message and
+// origin "dir/file.go:line". Messages are the part of the redacted message,
+// see WithRedaction, that each layer contributes, then filtered by
+// WithDebugSanitizer, so that under the default redaction 5xx responses
+// show types and origins and no message of their own. It is off by default
+// and must stay off in production.
+func SetDebugResponses(enabled bool) {
+	debugResponses.Store(enabled)
+}
+
+// WithDebugSanitizer sets a function applied to the messages of the debug
+// layers, see SetDebugResponses.
+func WithDebugSanitizer(fn func(msg string) string) Option {
+	return func(w *Writer) {
+		w.sanitize = fn
+	}
+}
+
+func (w *Writer) debugLayers(status int, err error) []map[string]interface{} {
+	var layers []map[string]interface{}
+	for e := err; e != nil; e = terrors.Unwrap(e) {
+		layer := map[string]interface{}{}
+		if te, ok := e.(terrors.TypedError); ok {
+			layer["type"] = w.typeValue(te.Type())
+		}
+
+		msg := w.message(status, e)
+		if next := terrors.Unwrap(e); next != nil {
+			msg = strings.TrimSuffix(strings.TrimSuffix(msg, w.message(status, next)), ": ")
+		}
+		if w.sanitize != nil && msg != "" {
+			msg = w.sanitize(msg)
+		}
+		if msg != "" {
+			layer["message"] = msg
+		}
+
+		if st, ok := e.(terrors.StackTracer); ok && len(st.StackTrace()) > 0 {
+			for _, f := range terrors.Frames(ownStack{st}) {
+				if f.File != "" {
+					layer["origin"] = f.Location()
+					break
+				}
+			}
+		}
+
+		if len(layer) > 0 {
+			layers = append(layers, layer)
+		}
+	}
+	return layers
+}
+
+// ownStack holds the stack of a single layer, for Frames to resolve it rather
+// than the innermost stack of the chain.
+type ownStack struct {
+	terrors.StackTracer
+}
+
+func (ownStack) Error() string {
+	return ""
+}
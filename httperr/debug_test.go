@@ -0,0 +1,114 @@
+package httperr
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/thamaji/terrors"
+)
+
+// causer wraps an error with Cause() only, as older libraries do.
+type causer struct {
+	cause error
+}
+
+func (c causer) Error() string { return "query: " + c.cause.Error() }
+func (c causer) Cause() error  { return c.cause }
+
+func debugErr(t terrors.Type) error {
+	cause := causer{terrors.New(terrors.TypeNotExist, "no row")}
+	return terrors.Wrap(t, cause, "lookup")
+}
+
+// debugLines replaces NEW and WRAP in s with the lines where debugErr
+// creates and wraps its error.
+func debugLines(s string) string {
+	_, _, line, _ := terrors.Caller(debugErr(terrors.TypeInvalid))
+	return strings.NewReplacer("NEW", strconv.Itoa(line), "WRAP", strconv.Itoa(line+1)).Replace(s)
+}
+
+// debugOf returns the debug layers of body as JSON, for comparisons.
+func debugOf(t *testing.T, body map[string]interface{}) string {
+	t.Helper()
+
+	layers, ok := body["debug"]
+	if !ok {
+		return ""
+	}
+	b, err := json.Marshal(layers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestDebugResponses(t *testing.T) {
+	t.Cleanup(func() { SetDebugResponses(false) })
+
+	tests := []struct {
+		name string
+		w    *Writer
+		err  error
+		want string
+	}{
+		{
+			"client error",
+			NewWriter(),
+			debugErr(terrors.TypeInvalid),
+			`[{"message":"lookup","origin":"httperr/debug_test.go:WRAP","type":"invalid"},{"message":"query"},{"message":"no row","origin":"httperr/debug_test.go:NEW","type":"not_exist"}]`,
+		},
+		{
+			"redacted server error",
+			NewWriter(),
+			debugErr(terrors.TypeInternal),
+			`[{"origin":"httperr/debug_test.go:WRAP","type":"internal"},{"message":"Internal Server Error","origin":"httperr/debug_test.go:NEW","type":"not_exist"}]`,
+		},
+		{
+			"sanitized",
+			NewWriter(WithDebugSanitizer(strings.ToUpper)),
+			debugErr(terrors.TypeInvalid),
+			`[{"message":"LOOKUP","origin":"httperr/debug_test.go:WRAP","type":"invalid"},{"message":"QUERY"},{"message":"NO ROW","origin":"httperr/debug_test.go:NEW","type":"not_exist"}]`,
+		},
+		{
+			"custom redaction",
+			NewWriter(WithRedaction(func(status int, err error) string { return strings.ReplaceAll(err.Error(), "no row", "***") })),
+			debugErr(terrors.TypeInternal),
+			`[{"message":"lookup","origin":"httperr/debug_test.go:WRAP","type":"internal"},{"message":"query"},{"message":"***","origin":"httperr/debug_test.go:NEW","type":"not_exist"}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetDebugResponses(false)
+			_, off := write(t, tt.w, tt.err)
+			SetDebugResponses(true)
+			_, on := write(t, tt.w, tt.err)
+
+			if got := debugOf(t, off); got != "" {
+				t.Errorf("disabled: debug = %s", got)
+			}
+			if got, want := debugOf(t, on), debugLines(tt.want); got != want {
+				t.Errorf("enabled: debug = %s\nwant %s", got, want)
+			}
+
+			delete(on, "debug")
+			if !reflect.DeepEqual(on, off) {
+				t.Errorf("the rest of the body differs:\n%v\n%v", on, off)
+			}
+		})
+	}
+}
+
+func TestDebugResponsesForeign(t *testing.T) {
+	SetDebugResponses(true)
+	t.Cleanup(func() { SetDebugResponses(false) })
+
+	_, body := write(t, NewWriter(), errors.New("plain"))
+	if got := debugOf(t, body); got != `[{"message":"Internal Server Error"}]` {
+		t.Errorf("debug = %s", got)
+	}
+}
@@ -0,0 +1,126 @@
+package httperr
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/thamaji/terrors"
+)
+
+const (
+	maxBodySize    = 64 << 10
+	maxSnippetSize = 256
+)
+
+// FromResponse returns the error described by a non-2xx response, or nil for
+// a 2xx response. Bodies written by Writer (with or without an "error"
+// envelope) and RFC 7807 problem details are decoded; any other body becomes
+// the message of an error typed from the status. Statuses that do not denote
+// an error, such as 1xx and 3xx, yield TypeUnknown: the response was still
+// not the success the caller expected. At most 64KiB of the body are read
+// and the rest is drained so the connection can be reused; closing the body
+// is left to the caller.
+func FromResponse(resp *http.Response) error {
+	if resp == nil {
+		return terrors.New(terrors.TypeInternal, "httperr: nil response")
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	var body []byte
+	if resp.Body != nil {
+		body, _ = io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+		_, _ = io.Copy(io.Discard, resp.Body)
+	}
+
+	decoders := []func(status int, body []byte) (error, bool){decodeBody, decodeProblem}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType == "application/problem+json" {
+		decoders[0], decoders[1] = decoders[1], decoders[0]
+	}
+	for _, decode := range decoders {
+		if err, ok := decode(resp.StatusCode, body); ok {
+			return err
+		}
+	}
+
+	snippet := strings.TrimSpace(string(body))
+	if len(snippet) > maxSnippetSize {
+		// cut at a rune boundary, so that the message stays valid UTF-8
+		n := maxSnippetSize
+		for n > 0 && !utf8.RuneStart(snippet[n]) {
+			n--
+		}
+		snippet = snippet[:n]
+	}
+	if snippet == "" {
+		snippet = http.StatusText(resp.StatusCode)
+	}
+	return terrors.New(errorType(resp.StatusCode, ""), snippet)
+}
+
+// errorType returns the type named name, or the type of status when name is
+// not a type, never TypeNotError.
+func errorType(status int, name string) terrors.Type {
+	t, ok := terrors.ParseType(name)
+	if !ok {
+		t = terrors.TypeOfHTTPStatus(status)
+	}
+	if t == terrors.TypeNotError {
+		return terrors.TypeUnknown
+	}
+	return t
+}
+
+func decodeBody(status int, body []byte) (error, bool) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, false
+	}
+	if envelope, ok := fields["error"]; ok {
+		var nested map[string]json.RawMessage
+		if err := json.Unmarshal(envelope, &nested); err == nil {
+			fields = nested
+		}
+	}
+
+	var name, message string
+	if json.Unmarshal(fields["message"], &message) != nil || message == "" {
+		return nil, false
+	}
+	if json.Unmarshal(fields["type"], &name) != nil {
+		_ = json.Unmarshal(fields["code"], &name)
+	}
+
+	return terrors.New(errorType(status, name), message), true
+}
+
+func decodeProblem(status int, body []byte) (error, bool) {
+	var problem struct {
+		Type   string `json:"type"`
+		Title  string `json:"title"`
+		Status int    `json:"status"`
+		Detail string `json:"detail"`
+	}
+	if err := json.Unmarshal(body, &problem); err != nil {
+		return nil, false
+	}
+	if problem.Title == "" && problem.Detail == "" {
+		return nil, false
+	}
+
+	if problem.Status != 0 {
+		status = problem.Status
+	}
+	t := errorType(status, problem.Type[strings.LastIndexByte(problem.Type, '/')+1:])
+
+	message := problem.Detail
+	if message == "" {
+		message = problem.Title
+	}
+	return terrors.New(t, message), true
+}
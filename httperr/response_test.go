@@ -0,0 +1,95 @@
+package httperr
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/thamaji/terrors"
+)
+
+func response(status int, contentType string, body string) *http.Response {
+	rec := httptest.NewRecorder()
+	if contentType != "" {
+		rec.Header().Set("Content-Type", contentType)
+	}
+	rec.WriteHeader(status)
+	io.WriteString(rec, body)
+	return rec.Result()
+}
+
+func TestFromResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		resp    *http.Response
+		typ     terrors.Type
+		message string
+	}{
+		{"writer body", response(404, "application/json", `{"type":"not_exist","message":"no such user"}`), terrors.TypeNotExist, "no such user"},
+		{"envelope", response(409, "application/json", `{"error":{"code":"conflict","message":"busy"}}`), terrors.TypeConflict, "busy"},
+		{"unknown type name", response(403, "application/json", `{"type":"E_FORBIDDEN","message":"nope"}`), terrors.TypePermission, "nope"},
+		{"problem", response(400, "application/problem+json", `{"type":"https://example.com/invalid","title":"Bad","detail":"name is empty"}`), terrors.TypeInvalid, "name is empty"},
+		{"text", response(503, "text/plain", "  down for maintenance \n"), terrors.TypeUnavailable, "down for maintenance"},
+		{"empty", response(500, "", ""), terrors.TypeInternal, "Internal Server Error"},
+		{"redirect", response(302, "", ""), terrors.TypeUnknown, "Found"},
+		{"informational", response(103, "", ""), terrors.TypeUnknown, "Early Hints"},
+		{"not modified body", response(304, "application/json", `{"type":"not_error","message":"cached"}`), terrors.TypeUnknown, "cached"},
+		{"problem with 2xx status", response(418, "application/problem+json", `{"title":"odd","status":204}`), terrors.TypeUnknown, "odd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := FromResponse(tt.resp)
+			if err == nil {
+				t.Fatal("FromResponse() = nil")
+			}
+			if terrors.TypeOf(err) != tt.typ || err.Error() != tt.message {
+				t.Errorf("FromResponse() = %q (%v), want %q (%v)", err, terrors.TypeOf(err), tt.message, tt.typ)
+			}
+			if status := terrors.HTTPStatus(err); status < 400 {
+				t.Errorf("HTTPStatus() = %d, want an error status", status)
+			}
+		})
+	}
+}
+
+func TestFromResponseSuccess(t *testing.T) {
+	for _, status := range []int{200, 201, 204, 299} {
+		if err := FromResponse(response(status, "", "ok")); err != nil {
+			t.Errorf("FromResponse(%d) = %v", status, err)
+		}
+	}
+	if err := FromResponse(nil); terrors.TypeOf(err) != terrors.TypeInternal {
+		t.Errorf("FromResponse(nil) = %v", err)
+	}
+}
+
+func TestFromResponseLongBody(t *testing.T) {
+	err := FromResponse(response(502, "text/html", strings.Repeat("x", 1<<20)))
+	if len(err.Error()) != maxSnippetSize || terrors.TypeOf(err) != terrors.TypeUnavailable {
+		t.Errorf("FromResponse() message of %d bytes, type %v", len(err.Error()), terrors.TypeOf(err))
+	}
+}
+
+func TestFromResponseLongBodyUTF8(t *testing.T) {
+	// "é" is 2 bytes and "€" 3, so both straddle the bound
+	for _, body := range []string{strings.Repeat("x", maxSnippetSize-1) + "é", strings.Repeat("x", maxSnippetSize-2) + "€€"} {
+		msg := FromResponse(response(500, "text/plain", body)).Error()
+		if !utf8.ValidString(msg) || !strings.HasPrefix(body, msg) || len(msg) > maxSnippetSize || len(msg) < maxSnippetSize-2 {
+			t.Errorf("FromResponse() message ends with %q (%d bytes)", msg[len(msg)-4:], len(msg))
+		}
+	}
+}
+
+func TestFromResponseWriterRoundTrip(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewWriter(WithEnvelope("error")).Write(rec, httptest.NewRequest(http.MethodGet, "/", nil), terrors.New(terrors.TypeUnauthorized, "token expired"))
+
+	err := FromResponse(rec.Result())
+	if terrors.TypeOf(err) != terrors.TypeUnauthorized || err.Error() != "token expired" {
+		t.Errorf("FromResponse() = %q (%v)", err, terrors.TypeOf(err))
+	}
+}
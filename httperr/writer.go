@@ -0,0 +1,181 @@
+package httperr
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/thamaji/terrors"
+)
+
+const RequestIDHeader = "X-Request-Id"
+
+// FieldNames are the keys of the response body. Fields with an empty name
+// are left out.
+type FieldNames struct {
+	Type      string
+	Message   string
+	Details   string
+	RequestID string
+	ID        string
+	Reference string
+	Debug     string
+}
+
+type Option func(*Writer)
+
+// WithEnvelope nests the body under key, e.g. {"error": {...}}.
+func WithEnvelope(key string) Option {
+	return func(w *Writer) {
+		w.envelope = key
+	}
+}
+
+func WithFieldNames(names FieldNames) Option {
+	return func(w *Writer) {
+		w.names = names
+	}
+}
+
+// WithTypeValue replaces the value written for the type, which defaults to
+// Type.String().
+func WithTypeValue(fn func(t terrors.Type) string) Option {
+	return func(w *Writer) {
+		w.typeValue = fn
+	}
+}
+
+// WithDetails replaces the function choosing the details added to the body
+// when not nil. The default is the outermost payload attached with
+// terrors.WithDetails, marshaled by encoding/json, leaving out the sampling
+// decision of terrors.NewSampled.
+func WithDetails(fn func(err error) interface{}) Option {
+	return func(w *Writer) {
+		w.details = fn
+	}
+}
+
+// WithRedaction replaces the function choosing the message sent to clients.
+// The default sends err.Error() for 4xx statuses and the status text for
+// 5xx statuses.
+func WithRedaction(fn func(status int, err error) string) Option {
+	return func(w *Writer) {
+		w.message = fn
+	}
+}
+
+// WithRequestID replaces the function reading the request id from the
+// request, which defaults to the X-Request-Id header.
+func WithRequestID(fn func(r *http.Request) string) Option {
+	return func(w *Writer) {
+		w.requestID = fn
+	}
+}
+
+// Writer writes errors as JSON responses. The default body is
+// {"type": ..., "message": ..., "error_id": ..., "request_id": ...}, the same
+// shape as the ginterr and echoerr bodies, with "details" when the error
+// carries a payload. 5xx bodies also carry a "reference", see
+// terrors.Reference.
+type Writer struct {
+	envelope  string
+	names     FieldNames
+	typeValue func(t terrors.Type) string
+	details   func(err error) interface{}
+	message   func(status int, err error) string
+	requestID func(r *http.Request) string
+	sanitize  func(msg string) string
+}
+
+func NewWriter(opts ...Option) *Writer {
+	w := &Writer{
+		names: FieldNames{
+			Type:      "type",
+			Message:   "message",
+			Details:   "details",
+			RequestID: "request_id",
+			ID:        "error_id",
+			Reference: "reference",
+			Debug:     "debug",
+		},
+		typeValue: terrors.Type.String,
+		details:   details,
+		message:   redact,
+		requestID: requestID,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+func (w *Writer) Write(rw http.ResponseWriter, r *http.Request, err error) {
+	status, body := w.Body(r, err)
+
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+	rw.WriteHeader(status)
+	if r != nil && r.Method == http.MethodHead {
+		return
+	}
+	_ = json.NewEncoder(rw).Encode(body)
+}
+
+// Body returns the status and the body Write would send for err.
+func (w *Writer) Body(r *http.Request, err error) (int, map[string]interface{}) {
+	status := terrors.HTTPStatus(err)
+
+	fields := map[string]interface{}{}
+	set := func(name string, value interface{}) {
+		if name != "" {
+			fields[name] = value
+		}
+	}
+
+	set(w.names.Type, w.typeValue(terrors.TypeOf(err)))
+	set(w.names.Message, w.message(status, err))
+	if w.details != nil {
+		if details := w.details(err); details != nil {
+			set(w.names.Details, details)
+		}
+	}
+	if id := terrors.ID(err); id != "" {
+		set(w.names.ID, id)
+	}
+	if status >= http.StatusInternalServerError {
+		set(w.names.Reference, terrors.Reference(err))
+	}
+	if r != nil {
+		if id := w.requestID(r); id != "" {
+			set(w.names.RequestID, id)
+		}
+	}
+
+	if debugResponses.Load() {
+		if layers := w.debugLayers(status, err); len(layers) > 0 {
+			set(w.names.Debug, layers)
+		}
+	}
+
+	if w.envelope == "" {
+		return status, fields
+	}
+	return status, map[string]interface{}{w.envelope: fields}
+}
+
+func details(err error) interface{} {
+	d, _ := terrors.Details[interface{}](err)
+	if _, ok := d.(terrors.StackSampling); ok {
+		return nil
+	}
+	return d
+}
+
+func redact(status int, err error) string {
+	if status >= http.StatusInternalServerError {
+		return http.StatusText(status)
+	}
+	return err.Error()
+}
+
+func requestID(r *http.Request) string {
+	return r.Header.Get(RequestIDHeader)
+}
@@ -0,0 +1,109 @@
+package httperr
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/thamaji/terrors"
+)
+
+type quotaExceeded struct {
+	Limit int `json:"limit"`
+	Used  int `json:"used"`
+}
+
+// write sends err with w and decodes the JSON body.
+func write(t *testing.T, w *Writer, err error) (int, map[string]interface{}) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	w.Write(rec, httptest.NewRequest(http.MethodGet, "/", nil), err)
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, body
+}
+
+func TestWriterDetails(t *testing.T) {
+	err := terrors.WithDetails(terrors.New(terrors.TypeUnavailable, "quota exceeded"), quotaExceeded{Limit: 10, Used: 11})
+
+	status, body := write(t, NewWriter(), err)
+	details, _ := body["details"].(map[string]interface{})
+	if status != http.StatusServiceUnavailable || details["limit"] != float64(10) || details["used"] != float64(11) {
+		t.Errorf("got %d %v", status, body)
+	}
+
+	_, body = write(t, NewWriter(), terrors.NewSampled(terrors.TypeInvalid, "bad", 1))
+	if _, ok := body["details"]; ok {
+		t.Errorf("body carries the sampling decision: %v", body)
+	}
+
+	_, body = write(t, NewWriter(WithDetails(func(err error) interface{} { return nil })), err)
+	if _, ok := body["details"]; ok {
+		t.Errorf("body = %v, want no details", body)
+	}
+}
+
+func TestWritersOnTheSameError(t *testing.T) {
+	err := terrors.WithDetails(terrors.Wrap(terrors.TypeInvalid, terrors.New(terrors.TypeNotExist, "no such user"), "lookup"), []string{"name"})
+
+	plain := NewWriter()
+	custom := NewWriter(
+		WithEnvelope("error"),
+		WithFieldNames(FieldNames{Type: "code", Message: "message", Details: "fields", RequestID: "trace"}),
+		WithTypeValue(func(t terrors.Type) string { return "E_" + t.String() }),
+		WithRequestID(func(r *http.Request) string { return "trace-1" }),
+		WithRedaction(func(status int, err error) string { return "redacted" }),
+	)
+
+	status, body := write(t, plain, err)
+	if status != http.StatusBadRequest || body["type"] != "invalid" || body["message"] != "lookup: no such user" || body["error_id"] != terrors.ID(err) {
+		t.Errorf("default writer: %d %v", status, body)
+	}
+	if fields, _ := body["details"].([]interface{}); len(fields) != 1 || fields[0] != "name" {
+		t.Errorf("default writer details = %v", body["details"])
+	}
+
+	status, body = write(t, custom, err)
+	envelope, _ := body["error"].(map[string]interface{})
+	if status != http.StatusBadRequest || len(body) != 1 || envelope == nil {
+		t.Fatalf("custom writer: %d %v", status, body)
+	}
+	want := map[string]interface{}{"code": "E_invalid", "message": "redacted", "fields": []interface{}{"name"}, "trace": "trace-1"}
+	if fmt.Sprint(envelope) != fmt.Sprint(want) {
+		t.Errorf("custom writer body = %v, want %v", envelope, want)
+	}
+}
+
+func TestWriterRedaction(t *testing.T) {
+	err := terrors.New(terrors.TypeInternal, "db password is hunter2")
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	NewWriter().Write(rec, req, err)
+
+	var body map[string]interface{}
+	if jerr := json.Unmarshal(rec.Body.Bytes(), &body); jerr != nil {
+		t.Fatal(jerr)
+	}
+	if rec.Code != http.StatusInternalServerError || body["message"] != "Internal Server Error" || body["request_id"] != "req-1" || body["reference"] != terrors.Reference(err) {
+		t.Errorf("got %d %v", rec.Code, body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+}
+
+func TestWriterHead(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewWriter().Write(rec, httptest.NewRequest(http.MethodHead, "/", nil), terrors.New(terrors.TypeNotExist, "missing"))
+	if rec.Code != http.StatusNotFound || rec.Body.Len() != 0 {
+		t.Errorf("got %d %q", rec.Code, rec.Body.String())
+	}
+}
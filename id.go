@@ -0,0 +1,75 @@
+package terrors
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"sync/atomic"
+)
+
+var (
+	idsDisabled atomic.Bool
+	idCounter   atomic.Uint64
+)
+
+func init() {
+	var seed [8]byte
+	_, _ = rand.Read(seed[:])
+	idCounter.Store(binary.LittleEndian.Uint64(seed[:]))
+}
+
+// DisableIDs stops New, Errorf, Wrap and the other constructors from
+// assigning instance IDs. Errors created while disabled have no ID.
+func DisableIDs(disabled bool) {
+	idsDisabled.Store(disabled)
+}
+
+// ID returns the instance ID of err, a 13 character string assigned when the
+// error was created, or "" when there is none. The innermost ID of the chain
+// wins, so wrapping an error does not change its identity.
+func ID(err error) string {
+	var id uint64
+	for e := err; e != nil; e = unwrapOnce(e) {
+		if i, ok := e.(interface{ instanceID() uint64 }); ok && i.instanceID() != 0 {
+			id = i.instanceID()
+		}
+	}
+	if id == 0 {
+		return ""
+	}
+
+	return formatID(id)
+}
+
+// newID returns a unique id for a new error. The ids are a seeded counter
+// scrambled by splitmix64, a bijection of the 64-bit values, so they are
+// cheap and look random but do not repeat within a process before 2^64
+// errors. 0 stands for no id and is skipped. Formatting is left to ID.
+func newID() uint64 {
+	if idsDisabled.Load() {
+		return 0
+	}
+
+	for {
+		if z := splitmix(idCounter.Add(0x9e3779b97f4a7c15)); z != 0 {
+			return z
+		}
+	}
+}
+
+func splitmix(z uint64) uint64 {
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+const idAlphabet = "0123456789abcdefghjkmnpqrstvwxyz"
+
+// formatID writes all 64 bits of id, 5 per character.
+func formatID(id uint64) string {
+	var b [13]byte
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i] = idAlphabet[id&31]
+		id >>= 5
+	}
+	return string(b[:])
+}
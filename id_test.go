@@ -0,0 +1,47 @@
+package terrors
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestID(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 1000; i++ {
+		id := ID(New(TypeInternal, "x"))
+		if len(id) != 13 || strings.Trim(id, idAlphabet) != "" {
+			t.Fatalf("ID() = %q", id)
+		}
+		if seen[id] {
+			t.Fatalf("ID() %q repeated", id)
+		}
+		seen[id] = true
+	}
+
+	// every bit of the id is kept
+	for _, tt := range []struct {
+		id   uint64
+		want string
+	}{
+		{1, "0000000000001"},
+		{1 << 63, "8000000000000"},
+		{^uint64(0), "fzzzzzzzzzzzz"},
+	} {
+		if got := formatID(tt.id); got != tt.want {
+			t.Errorf("formatID(%#x) = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestIDWrapped(t *testing.T) {
+	err := New(TypeNotExist, "missing")
+	if id := ID(Wrap(TypeInternal, err, "load")); id != ID(err) {
+		t.Errorf("ID() of the wrapped error = %q, want %q", id, ID(err))
+	}
+
+	DisableIDs(true)
+	defer DisableIDs(false)
+	if id := ID(New(TypeInternal, "x")); id != "" {
+		t.Errorf("ID() while disabled = %q", id)
+	}
+}
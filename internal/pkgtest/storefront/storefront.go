@@ -0,0 +1,10 @@
+// Package storefront creates errors from its own functions, for the tests of
+// terrors.RegisterPackageDefault.
+package storefront
+
+import "github.com/pkg/errors"
+
+// Fail returns an untyped error with a stack starting in this package.
+func Fail() error {
+	return errors.New("storefront failed")
+}
@@ -0,0 +1,29 @@
+package terrors
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// FromIOError classifies the io sentinels found in err's chain:
+// io.ErrUnexpectedEOF and io.ErrClosedPipe become TypeInternal, and io.EOF
+// becomes TypeNotExist when eofNotExist is set and is returned untouched
+// otherwise. Other errors are returned untouched. The result still matches
+// the original sentinel with errors.Is.
+func FromIOError(err error, eofNotExist bool) error {
+	var t Type
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.ErrClosedPipe):
+		t = TypeInternal
+	case errors.Is(err, io.EOF) && eofNotExist:
+		t = TypeNotExist
+	default:
+		return err
+	}
+
+	stack := errors.New("").(StackTracer).StackTrace()
+	return newWithStack(t, err, stack[1:])
+}
@@ -0,0 +1,54 @@
+package terrors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestFromIOError(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		eofNotExist bool
+		want        Type
+		untouched   bool
+	}{
+		{"unexpected EOF", io.ErrUnexpectedEOF, false, TypeInternal, false},
+		{"closed pipe", io.ErrClosedPipe, false, TypeInternal, false},
+		{"EOF", io.EOF, false, TypeUnknown, true},
+		{"EOF as not exist", io.EOF, true, TypeNotExist, false},
+		{"wrapped unexpected EOF", fmt.Errorf("read header: %w", io.ErrUnexpectedEOF), false, TypeInternal, false},
+		{"wrapped EOF", fmt.Errorf("next row: %w", io.EOF), true, TypeNotExist, false},
+		{"short write", io.ErrShortWrite, true, TypeUnknown, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FromIOError(tt.err, tt.eofNotExist)
+			if tt.untouched {
+				if got != tt.err {
+					t.Errorf("FromIOError() = %#v, want the error untouched", got)
+				}
+				return
+			}
+			if TypeOf(got) != tt.want {
+				t.Errorf("TypeOf() = %v, want %v", TypeOf(got), tt.want)
+			}
+			if !stderrors.Is(got, Cause(tt.err)) {
+				t.Errorf("errors.Is(%v, %v) = false", got, Cause(tt.err))
+			}
+			if got.Error() != tt.err.Error() {
+				t.Errorf("Error() = %q, want %q", got.Error(), tt.err.Error())
+			}
+			if st, _ := StackTrace(got); fmt.Sprintf("%n", st[0]) != "TestFromIOError.func1" {
+				t.Errorf("stack starts at %n", st[0])
+			}
+		})
+	}
+
+	if FromIOError(nil, true) != nil {
+		t.Error("FromIOError(nil) != nil")
+	}
+}
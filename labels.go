@@ -0,0 +1,118 @@
+package terrors
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/pprof"
+	"strconv"
+	"sync/atomic"
+)
+
+var captureLabels atomic.Bool
+
+// CaptureGoroutineLabels makes New, Errorf, WithStack, Wrap and Wrapf record
+// the id of the goroutine creating the error under the "goroutine" label.
+// It is off by default. The pprof labels of a goroutine are only reachable
+// through its context; attach them with WithLabels.
+func CaptureGoroutineLabels(enabled bool) {
+	captureLabels.Store(enabled)
+}
+
+func goroutineLabels() map[string]string {
+	if !captureLabels.Load() {
+		return nil
+	}
+	return map[string]string{"goroutine": strconv.FormatUint(goroutineID(), 10)}
+}
+
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := bytes.TrimPrefix(buf[:runtime.Stack(buf[:], false)], []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
+
+// WithLabels annotates err with the pprof labels carried by ctx.
+func WithLabels(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	labels := map[string]string{}
+	pprof.ForLabels(ctx, func(key, value string) bool {
+		labels[key] = value
+		return true
+	})
+	if len(labels) == 0 {
+		return err
+	}
+
+	return &withLabels{cause: err, labels: labels}
+}
+
+type withLabels struct {
+	cause  error
+	labels map[string]string
+}
+
+func (w *withLabels) Error() string {
+	return w.cause.Error()
+}
+
+func (w *withLabels) Cause() error {
+	return w.cause
+}
+
+func (w *withLabels) Unwrap() error {
+	return w.cause
+}
+
+func (w *withLabels) labelSet() map[string]string {
+	return w.labels
+}
+
+func (w *withLabels) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			formatVerbose(s, w)
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, w.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", w.Error())
+	}
+}
+
+// Labels returns the labels recorded along err's chain. When a key is set at
+// several layers the outermost value wins.
+func Labels(err error) map[string]string {
+	type labeler interface {
+		labelSet() map[string]string
+	}
+
+	var labels map[string]string
+	for ; err != nil; err = unwrapOnce(err) {
+		l, ok := err.(labeler)
+		if !ok {
+			continue
+		}
+		for k, v := range l.labelSet() {
+			if labels == nil {
+				labels = map[string]string{}
+			}
+			if _, ok := labels[k]; !ok {
+				labels[k] = v
+			}
+		}
+	}
+	return labels
+}
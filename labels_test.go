@@ -0,0 +1,69 @@
+package terrors
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime/pprof"
+	"strconv"
+	"testing"
+)
+
+func TestWithLabels(t *testing.T) {
+	var err error
+	pprof.Do(context.Background(), pprof.Labels("request", "r1", "tenant", "acme"), func(ctx context.Context) {
+		pprof.Do(ctx, pprof.Labels("tenant", "globex"), func(ctx context.Context) {
+			err = WithLabels(ctx, Wrap(TypeInternal, io.EOF, "read"))
+		})
+	})
+
+	want := map[string]string{"request": "r1", "tenant": "globex"}
+	if got := Labels(err); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Labels() = %v, want %v", got, want)
+	}
+	if err.Error() != "read: EOF" || TypeOf(err) != TypeInternal {
+		t.Errorf("WithLabels changed the error: %q %v", err, TypeOf(err))
+	}
+}
+
+func TestWithLabelsOutermostWins(t *testing.T) {
+	var err error
+	pprof.Do(context.Background(), pprof.Labels("step", "inner"), func(ctx context.Context) {
+		err = WithLabels(ctx, io.EOF)
+	})
+	pprof.Do(context.Background(), pprof.Labels("step", "outer"), func(ctx context.Context) {
+		err = WithLabels(ctx, fmt.Errorf("wrapped: %w", err))
+	})
+
+	if got := Labels(err)["step"]; got != "outer" {
+		t.Errorf("Labels()[step] = %q, want outer", got)
+	}
+}
+
+func TestWithLabelsWithoutLabels(t *testing.T) {
+	if err := WithLabels(context.Background(), io.EOF); err != io.EOF {
+		t.Errorf("WithLabels() = %#v, want the error unchanged", err)
+	}
+	if WithLabels(context.Background(), nil) != nil {
+		t.Error("WithLabels(nil) != nil")
+	}
+}
+
+func TestCaptureGoroutineLabels(t *testing.T) {
+	CaptureGoroutineLabels(true)
+	defer CaptureGoroutineLabels(false)
+
+	ch := make(chan error)
+	go func() { ch <- New(TypeInternal, "boom") }()
+	err := <-ch
+
+	id, _ := strconv.ParseUint(Labels(err)["goroutine"], 10, 64)
+	if id == 0 || id == goroutineID() {
+		t.Errorf("goroutine label = %q, want the id of the creating goroutine", Labels(err)["goroutine"])
+	}
+
+	CaptureGoroutineLabels(false)
+	if labels := Labels(New(TypeInternal, "boom")); labels != nil {
+		t.Errorf("Labels() = %v with capture off", labels)
+	}
+}
@@ -0,0 +1,60 @@
+package terrors
+
+import (
+	"fmt"
+)
+
+// LogFields returns err as a flat map for structured loggers. The keys are
+// stable:
+//
+//	error.message   err.Error()
+//	error.type      TypeOf(err).String()
+//	error.id        the instance ID, see ID
+//	error.op        []string, the operations of Ops
+//	error.origin    "file:line" of the first frame of the innermost stack
+//	error.labels.*  one key per label, see Labels
+//	error.secondary []string, the messages of the errors attached with
+//	                WithSecondary
+//	error.stack     []string, the innermost stack as StackLines returns it
+//	                (only when stack is true)
+//
+// Keys without a value are omitted. A nil error yields an empty map.
+func LogFields(err error, stack bool) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if err == nil {
+		return fields
+	}
+
+	fields["error.message"] = err.Error()
+	fields["error.type"] = TypeOf(err).String()
+
+	if id := ID(err); id != "" {
+		fields["error.id"] = id
+	}
+
+	if ops := Ops(err); len(ops) > 0 {
+		fields["error.op"] = ops
+	}
+
+	if _, file, line, ok := Caller(err); ok {
+		fields["error.origin"] = fmt.Sprintf("%s:%d", trimPath(file), line)
+	}
+
+	for k, v := range Labels(err) {
+		fields["error.labels."+k] = v
+	}
+
+	if secondary := Secondary(err); len(secondary) > 0 {
+		messages := make([]string, len(secondary))
+		for i, e := range secondary {
+			messages[i] = e.Error()
+		}
+		fields["error.secondary"] = messages
+	}
+
+	if lines := StackLines(err, 0); stack && len(lines) > 0 {
+		fields["error.stack"] = lines
+	}
+
+	return fields
+}
@@ -0,0 +1,92 @@
+package terrors
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func logFieldsErr() error {
+	err := WithSecondary(WithOp(Wrap(TypeInternal, formatOuter(), "load"), "store.Load"), io.ErrClosedPipe)
+
+	CaptureGoroutineLabels(true)
+	defer CaptureGoroutineLabels(false)
+	return labeled(err)
+}
+
+// keysOf returns the sorted keys of fields.
+func keysOf(fields map[string]interface{}) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestLogFieldsKeys(t *testing.T) {
+	err := logFieldsErr()
+
+	// dashboards depend on these keys, change them with care
+	base := []string{"error.id", "error.labels.tenant", "error.message", "error.op", "error.origin", "error.secondary", "error.type"}
+	tests := []struct {
+		stack bool
+		want  []string
+	}{
+		{false, base},
+		{true, append(append([]string{}, base...), "error.stack")},
+	}
+	for _, tt := range tests {
+		fields := LogFields(err, tt.stack)
+		want := append([]string{}, tt.want...)
+		sort.Strings(want)
+		if got := keysOf(fields); !reflect.DeepEqual(got, want) {
+			t.Errorf("LogFields(stack=%v) keys = %q, want %q", tt.stack, got, want)
+		}
+	}
+
+	fields := LogFields(err, true)
+	if fields["error.message"] != "load: outer: inner" || fields["error.type"] != "internal" || fields["error.labels.tenant"] != "acme" {
+		t.Errorf("LogFields() = %v", fields)
+	}
+	if ops := fields["error.op"].([]string); !reflect.DeepEqual(ops, []string{"store.Load"}) {
+		t.Errorf("error.op = %q", ops)
+	}
+	if secondary := fields["error.secondary"].([]string); !reflect.DeepEqual(secondary, []string{io.ErrClosedPipe.Error()}) {
+		t.Errorf("error.secondary = %q", secondary)
+	}
+	if fields["error.id"] != ID(err) {
+		t.Errorf("error.id = %v, want %s", fields["error.id"], ID(err))
+	}
+}
+
+func TestLogFieldsStack(t *testing.T) {
+	err := logFieldsErr()
+	fields := LogFields(err, true)
+
+	// the stack is the innermost one, where error.origin points
+	lines := fields["error.stack"].([]string)
+	if !reflect.DeepEqual(lines, StackLines(err, 0)) {
+		t.Errorf("error.stack = %q, want StackLines() %q", lines, StackLines(err, 0))
+	}
+	_, file, line, _ := Caller(err)
+	if !strings.HasPrefix(lines[0], "github.com/thamaji/terrors.formatInner ") || fields["error.origin"] != fmt.Sprintf("%s:%d", trimPath(file), line) {
+		t.Errorf("error.stack starts at %q, error.origin = %v", lines[0], fields["error.origin"])
+	}
+	if !strings.HasSuffix(lines[0], fmt.Sprint(fields["error.origin"])) {
+		t.Errorf("error.stack starts at %q, not at error.origin %v", lines[0], fields["error.origin"])
+	}
+}
+
+func TestLogFieldsMinimal(t *testing.T) {
+	fields := LogFields(io.EOF, true)
+	if got := keysOf(fields); !reflect.DeepEqual(got, []string{"error.message", "error.type"}) {
+		t.Errorf("LogFields(io.EOF) keys = %q", got)
+	}
+	if got := LogFields(nil, true); got == nil || len(got) != 0 {
+		t.Errorf("LogFields(nil) = %#v, want an empty map", got)
+	}
+}
@@ -0,0 +1,99 @@
+package terrors
+
+import (
+	"strings"
+)
+
+// Matcher reports whether an error matches. Matchers built by this package
+// inspect the whole chain and never match nil.
+type Matcher func(err error) bool
+
+func (m Matcher) Match(err error) bool {
+	return err != nil && m(err)
+}
+
+func (m Matcher) Or(other Matcher) Matcher {
+	return Or(m, other)
+}
+
+func (m Matcher) And(other Matcher) Matcher {
+	return And(m, other)
+}
+
+func (m Matcher) Not() Matcher {
+	return Not(m)
+}
+
+func MatchType(t Type) Matcher {
+	return func(err error) bool {
+		return HasType(err, t)
+	}
+}
+
+func MatchMessageContains(s string) Matcher {
+	return func(err error) bool {
+		return err != nil && strings.Contains(err.Error(), s)
+	}
+}
+
+func Or(matchers ...Matcher) Matcher {
+	return func(err error) bool {
+		for _, m := range matchers {
+			if m.Match(err) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func And(matchers ...Matcher) Matcher {
+	return func(err error) bool {
+		for _, m := range matchers {
+			if !m.Match(err) {
+				return false
+			}
+		}
+		return err != nil
+	}
+}
+
+func Not(m Matcher) Matcher {
+	return func(err error) bool {
+		return err != nil && !m.Match(err)
+	}
+}
+
+type SwitchCase struct {
+	matcher Matcher
+	handler func(err error) error
+}
+
+func Case(m Matcher, handler func(err error) error) SwitchCase {
+	return SwitchCase{matcher: m, handler: handler}
+}
+
+func Default(handler func(err error) error) SwitchCase {
+	return SwitchCase{handler: handler}
+}
+
+// Switch calls the handler of the first case matching err, or of the first
+// Default case when none does, and returns its result. Nothing is called for
+// a nil error, and err is returned when no case applies.
+func Switch(err error, cases ...SwitchCase) error {
+	if err == nil {
+		return nil
+	}
+
+	for _, c := range cases {
+		if c.matcher != nil && c.matcher.Match(err) {
+			return c.handler(err)
+		}
+	}
+	for _, c := range cases {
+		if c.matcher == nil {
+			return c.handler(err)
+		}
+	}
+	return err
+}
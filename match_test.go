@@ -0,0 +1,104 @@
+package terrors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestMatchers(t *testing.T) {
+	notExist := Wrap(TypeInternal, New(TypeNotExist, "user not found"), "load")
+	timeout := fmt.Errorf("call: %w", New(TypeTimeout, "deadline"))
+	joined := stderrors.Join(io.EOF, New(TypeConflict, "busy"))
+
+	always := Matcher(func(error) bool { return true })
+
+	tests := []struct {
+		name string
+		m    Matcher
+		err  error
+		want bool
+	}{
+		{"type outer", MatchType(TypeInternal), notExist, true},
+		{"type inner", MatchType(TypeNotExist), notExist, true},
+		{"type absent", MatchType(TypeTimeout), notExist, false},
+		{"type through foreign", MatchType(TypeTimeout), timeout, true},
+		{"type in join", MatchType(TypeConflict), joined, true},
+		{"message", MatchMessageContains("not found"), notExist, true},
+		{"message absent", MatchMessageContains("timeout"), notExist, false},
+		{"or", Or(MatchType(TypeTimeout), MatchType(TypeNotExist)), notExist, true},
+		{"or none", Or(MatchType(TypeTimeout), MatchType(TypeCanceled)), notExist, false},
+		{"or empty", Or(), notExist, false},
+		{"and", And(MatchType(TypeNotExist), MatchMessageContains("user")), notExist, true},
+		{"and one fails", And(MatchType(TypeNotExist), MatchMessageContains("group")), notExist, false},
+		{"and empty", And(), notExist, true},
+		{"not", Not(MatchType(TypeTimeout)), notExist, true},
+		{"not matching", Not(MatchType(TypeNotExist)), notExist, false},
+		{"method chain", MatchType(TypeTimeout).Or(MatchType(TypeNotExist)).And(MatchMessageContains("load")).Not(), notExist, false},
+
+		{"nil type", MatchType(TypeNotError), nil, false},
+		{"nil message", MatchMessageContains(""), nil, false},
+		{"nil or", Or(always), nil, false},
+		{"nil and", And(always), nil, false},
+		{"nil and empty", And(), nil, false},
+		{"nil not", Not(MatchType(TypeTimeout)), nil, false},
+		{"nil custom", always, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.m.Match(tt.err); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSwitch(t *testing.T) {
+	handled := func(name string) func(error) error {
+		return func(err error) error { return fmt.Errorf("%s: %w", name, err) }
+	}
+	cases := []SwitchCase{
+		Case(MatchType(TypeNotExist), handled("not exist")),
+		Default(handled("default")),
+		Case(MatchType(TypeTimeout), handled("timeout")),
+		Case(MatchMessageContains("deadline"), handled("deadline")),
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"first case", Wrap(TypeInternal, New(TypeNotExist, "missing"), "load"), "not exist: load: missing"},
+		{"case after default", New(TypeTimeout, "deadline"), "timeout: deadline"},
+		{"default", io.EOF, "default: EOF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Switch(tt.err, cases...); got == nil || got.Error() != tt.want {
+				t.Errorf("Switch() = %v, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSwitchNoCase(t *testing.T) {
+	called := false
+	handler := func(err error) error { called = true; return nil }
+
+	if got := Switch(nil, Case(MatchType(TypeNotExist), handler), Default(handler)); got != nil || called {
+		t.Errorf("Switch(nil) = %v, called = %v", got, called)
+	}
+	if got := Switch(io.EOF, Case(MatchType(TypeNotExist), handler)); got != io.EOF || called {
+		t.Errorf("Switch() without a matching case = %v, called = %v", got, called)
+	}
+	if got := Switch(io.EOF); got != io.EOF {
+		t.Errorf("Switch() without cases = %v", got)
+	}
+	if got := Switch(io.EOF, Case(MatchType(TypeUnknown).Not(), handler), Default(handler)); got != nil || !called {
+		t.Errorf("Switch() = %v, called = %v; want the handler result", got, called)
+	}
+}
@@ -0,0 +1,69 @@
+package mongoerr
+
+import (
+	"errors"
+
+	"github.com/thamaji/terrors"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	codeUnauthorized         = 13
+	codeAuthenticationFailed = 18
+	codeShutdownInProgress   = 91
+	codeWriteConflict        = 112
+	codeInterruptedShutdown  = 11600
+)
+
+// Classify types driver errors found in err's chain: missing documents,
+// duplicate keys, timeouts, network and transient failures, and
+// authorization errors. The original error stays in the chain. Typed and
+// unrecognized errors are returned unchanged.
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := terrors.TypeOk(err); ok {
+		return err
+	}
+
+	t, ok := classify(err)
+	if !ok {
+		return err
+	}
+	return terrors.WithStack(t, err)
+}
+
+func classify(err error) (terrors.Type, bool) {
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return terrors.TypeNotExist, true
+	case errors.Is(err, mongo.ErrNilDocument), errors.Is(err, mongo.ErrNilValue), errors.Is(err, mongo.ErrEmptySlice):
+		return terrors.TypeInvalid, true
+	case mongo.IsDuplicateKeyError(err):
+		return terrors.TypeExist, true
+	case mongo.IsTimeout(err):
+		return terrors.TypeTimeout, true
+	case mongo.IsNetworkError(err):
+		return terrors.TypeUnavailable, true
+	}
+
+	var se mongo.ServerError
+	if !errors.As(err, &se) {
+		return terrors.TypeUnknown, false
+	}
+
+	switch {
+	case se.HasErrorCode(codeUnauthorized):
+		return terrors.TypePermission, true
+	case se.HasErrorCode(codeAuthenticationFailed):
+		return terrors.TypeUnauthorized, true
+	case se.HasErrorCode(codeWriteConflict):
+		return terrors.TypeConflict, true
+	case se.HasErrorCode(codeShutdownInProgress), se.HasErrorCode(codeInterruptedShutdown):
+		return terrors.TypeUnavailable, true
+	case se.HasErrorLabel("TransientTransactionError"), se.HasErrorLabel("RetryableWriteError"):
+		return terrors.TypeUnavailable, true
+	}
+	return terrors.TypeUnknown, false
+}
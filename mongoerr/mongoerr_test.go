@@ -0,0 +1,64 @@
+package mongoerr
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/thamaji/terrors"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want terrors.Type
+	}{
+		{"no documents", fmt.Errorf("find user: %w", mongo.ErrNoDocuments), terrors.TypeNotExist},
+		{"nil document", mongo.ErrNilDocument, terrors.TypeInvalid},
+		{"duplicate key write", mongo.WriteException{
+			WriteErrors: mongo.WriteErrors{{Index: 0, Code: 11000, Message: "E11000 duplicate key error"}},
+		}, terrors.TypeExist},
+		{"duplicate key bulk", mongo.BulkWriteException{
+			WriteErrors: []mongo.BulkWriteError{{WriteError: mongo.WriteError{Code: 11000}}},
+		}, terrors.TypeExist},
+		{"write conflict", mongo.WriteException{
+			WriteErrors: mongo.WriteErrors{{Code: codeWriteConflict, Message: "WriteConflict"}},
+		}, terrors.TypeConflict},
+		{"write concern shutdown", mongo.WriteException{
+			WriteConcernError: &mongo.WriteConcernError{Code: codeShutdownInProgress, Message: "shutdown"},
+		}, terrors.TypeUnavailable},
+		{"unauthorized", mongo.CommandError{Code: codeUnauthorized, Name: "Unauthorized"}, terrors.TypePermission},
+		{"authentication failed", fmt.Errorf("connect: %w", mongo.CommandError{Code: codeAuthenticationFailed}), terrors.TypeUnauthorized},
+		{"interrupted", mongo.CommandError{Code: codeInterruptedShutdown}, terrors.TypeUnavailable},
+		{"transient", mongo.CommandError{Code: 251, Labels: []string{"TransientTransactionError"}}, terrors.TypeUnavailable},
+		{"timeout", mongo.CommandError{Code: 50, Name: "MaxTimeMSExpired"}, terrors.TypeTimeout},
+		{"deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), terrors.TypeTimeout},
+		{"network", mongo.CommandError{Labels: []string{"NetworkError"}}, terrors.TypeUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Classify(tt.err)
+			if terrors.TypeOf(got) != tt.want {
+				t.Errorf("TypeOf() = %v, want %v", terrors.TypeOf(got), tt.want)
+			}
+			if !reflect.DeepEqual(terrors.Cause(got), terrors.Cause(tt.err)) || got.Error() != tt.err.Error() {
+				t.Errorf("Classify() lost the original error: %v", got)
+			}
+		})
+	}
+}
+
+func TestClassifyUnchanged(t *testing.T) {
+	unknown := mongo.CommandError{Code: 2, Name: "BadValue"}
+	typed := terrors.Wrap(terrors.TypeInvalid, mongo.ErrNoDocuments, "find")
+
+	for _, err := range []error{nil, unknown, typed, fmt.Errorf("plain")} {
+		if got := Classify(err); fmt.Sprint(got) != fmt.Sprint(err) || terrors.TypeOf(got) != terrors.TypeOf(err) {
+			t.Errorf("Classify(%v) = %v, want it unchanged", err, got)
+		}
+	}
+}
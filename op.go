@@ -0,0 +1,122 @@
+package terrors
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// WithOp annotates err with the name of the operation that failed, such as
+// "user.Load". It does not change err's message or type; see Ops.
+func WithOp(err error, op string) error {
+	if err == nil || op == "" {
+		return err
+	}
+	return &withOp{cause: err, op: op}
+}
+
+// Ops returns the operations annotating err's chain, outermost first.
+func Ops(err error) []string {
+	var ops []string
+	for ; err != nil; err = unwrapOnce(err) {
+		if w, ok := err.(*withOp); ok {
+			ops = append(ops, w.op)
+		}
+	}
+	return ops
+}
+
+// NewAuto is like New but also annotates the error with the name of the
+// calling function as its op, as "pkg.Func" or "pkg.Type.Method". Closures
+// are named after the function enclosing them.
+func NewAuto(t Type, msg string) error {
+	stack := errors.New(msg).(StackTracer).StackTrace()[1:]
+	f := newFundamental(t, msg, stack)
+	return WithOp(f, stackOp(stack))
+}
+
+// WrapAuto is like Wrap but also annotates the error with the name of the
+// calling function as its op, see NewAuto.
+func WrapAuto(t Type, err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+	stack := errors.New(msg).(StackTracer).StackTrace()[1:]
+	w := newWrapped(t, err, msg, stack)
+	return WithOp(w, stackOp(stack))
+}
+
+func stackOp(stack errors.StackTrace) string {
+	if len(stack) == 0 {
+		return ""
+	}
+	function, _, _ := frameInfo(stack[0])
+	return funcOp(function)
+}
+
+// funcOp turns a function name as reported by the runtime, such as
+// "example.com/app/store.(*DB).Load.func1", into "store.DB.Load".
+func funcOp(function string) string {
+	if i := strings.LastIndexByte(function, '/'); i >= 0 {
+		function = function[i+1:]
+	}
+	function = strings.ReplaceAll(function, "[...]", "")
+
+	parts := strings.Split(function, ".")
+	for len(parts) > 2 && isClosure(parts[len(parts)-1]) {
+		parts = parts[:len(parts)-1]
+	}
+	for i, p := range parts {
+		parts[i] = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(p, "("), "*"), ")")
+	}
+	return strings.Join(parts, ".")
+}
+
+func isClosure(name string) bool {
+	name = strings.TrimPrefix(name, "func")
+	name = strings.TrimPrefix(name, "gowrap")
+	name = strings.TrimPrefix(name, "deferwrap")
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+type withOp struct {
+	cause error
+	op    string
+}
+
+func (w *withOp) Error() string {
+	return w.cause.Error()
+}
+
+func (w *withOp) Cause() error {
+	return w.cause
+}
+
+func (w *withOp) Unwrap() error {
+	return w.cause
+}
+
+func (w *withOp) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			formatVerbose(s, w)
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, w.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", w.Error())
+	}
+}
@@ -0,0 +1,77 @@
+package terrors
+
+import (
+	"io"
+	"testing"
+)
+
+type opStore struct{}
+
+func (opStore) Get() error {
+	return NewAuto(TypeNotExist, "missing")
+}
+
+func (*opStore) Put() error {
+	return WrapAuto(TypeInternal, io.ErrShortWrite, "put")
+}
+
+func opLoad() error {
+	return NewAuto(TypeNotExist, "missing")
+}
+
+func opClosure() error {
+	load := func() error {
+		nested := func() error { return WrapAuto(TypeInternal, io.EOF, "read") }
+		return nested()
+	}
+	return load()
+}
+
+func opGeneric[T any]() error {
+	return NewAuto(TypeInvalid, "bad")
+}
+
+func TestAutoOp(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"function", opLoad(), "terrors.opLoad"},
+		{"value method", opStore{}.Get(), "terrors.opStore.Get"},
+		{"pointer method", (&opStore{}).Put(), "terrors.opStore.Put"},
+		{"closure", opClosure(), "terrors.opClosure"},
+		{"generic function", opGeneric[int](), "terrors.opGeneric"},
+	}
+	for _, tt := range tests {
+		if ops := Ops(tt.err); len(ops) != 1 || ops[0] != tt.want {
+			t.Errorf("%s: Ops() = %q, want [%s]", tt.name, ops, tt.want)
+		}
+	}
+
+	if err := WrapAuto(TypeInternal, nil, "x"); err != nil {
+		t.Errorf("WrapAuto(nil) = %v", err)
+	}
+}
+
+func TestFuncOp(t *testing.T) {
+	tests := []struct {
+		function string
+		want     string
+	}{
+		{"main.main", "main.main"},
+		{"example.com/app/store.Load", "store.Load"},
+		{"example.com/app/store.(*DB).Load", "store.DB.Load"},
+		{"example.com/app/store.DB.Load", "store.DB.Load"},
+		{"example.com/app/store.(*DB).Load.func1", "store.DB.Load"},
+		{"example.com/app/store.Load.func2.1", "store.Load"},
+		{"example.com/app/store.Load.gowrap1", "store.Load"},
+		{"example.com/app/store.Map[...]", "store.Map"},
+		{"example.com/app/store.(*Cache[...]).Get", "store.Cache.Get"},
+	}
+	for _, tt := range tests {
+		if got := funcOp(tt.function); got != tt.want {
+			t.Errorf("funcOp(%q) = %q, want %q", tt.function, got, tt.want)
+		}
+	}
+}
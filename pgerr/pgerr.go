@@ -0,0 +1,102 @@
+package pgerr
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+	"github.com/thamaji/terrors"
+)
+
+var (
+	mu sync.RWMutex
+	// codes maps SQLSTATE codes, or two character SQLSTATE classes, to types.
+	codes = map[string]terrors.Type{
+		"23502": terrors.TypeInvalid,      // not_null_violation
+		"23503": terrors.TypeInvalid,      // foreign_key_violation
+		"23505": terrors.TypeExist,        // unique_violation
+		"23514": terrors.TypeInvalid,      // check_violation
+		"23P01": terrors.TypeConflict,     // exclusion_violation
+		"28000": terrors.TypeUnauthorized, // invalid_authorization_specification
+		"28P01": terrors.TypeUnauthorized, // invalid_password
+		"40001": terrors.TypeConflict,     // serialization_failure
+		"40P01": terrors.TypeConflict,     // deadlock_detected
+		"42501": terrors.TypePermission,   // insufficient_privilege
+		"55P03": terrors.TypeConflict,     // lock_not_available
+		"57014": terrors.TypeCanceled,     // query_canceled
+		"57P01": terrors.TypeUnavailable,  // admin_shutdown
+		"57P03": terrors.TypeUnavailable,  // cannot_connect_now
+
+		"08": terrors.TypeUnavailable, // connection_exception
+		"22": terrors.TypeInvalid,     // data_exception
+		"23": terrors.TypeInvalid,     // integrity_constraint_violation
+		"40": terrors.TypeConflict,    // transaction_rollback
+		"53": terrors.TypeUnavailable, // insufficient_resources
+		"XX": terrors.TypeInternal,    // internal_error
+	}
+)
+
+// Register maps a SQLSTATE code, or a two character SQLSTATE class, to t.
+// Codes take precedence over classes.
+func Register(code string, t terrors.Type) {
+	mu.Lock()
+	codes[code] = t
+	mu.Unlock()
+}
+
+// Classify types err from the SQLSTATE of the *pgconn.PgError or *pq.Error in
+// its chain, or from the database/sql sentinels. The original error stays in
+// the chain. Typed and unrecognized errors are returned unchanged.
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := terrors.TypeOk(err); ok {
+		return err
+	}
+
+	t, ok := classify(err)
+	if !ok {
+		return err
+	}
+	return terrors.WithStack(t, err)
+}
+
+func classify(err error) (terrors.Type, bool) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return lookup(pgErr.Code)
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return lookup(string(pqErr.Code))
+	}
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return terrors.TypeNotExist, true
+	case errors.Is(err, sql.ErrConnDone):
+		return terrors.TypeUnavailable, true
+	case errors.Is(err, sql.ErrTxDone):
+		return terrors.TypeInternal, true
+	}
+	return terrors.TypeUnknown, false
+}
+
+func lookup(code string) (terrors.Type, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if t, ok := codes[code]; ok {
+		return t, true
+	}
+	if len(code) == 5 {
+		if t, ok := codes[code[:2]]; ok {
+			return t, true
+		}
+	}
+	return terrors.TypeUnknown, false
+}
@@ -0,0 +1,85 @@
+package pgerr
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+	"github.com/thamaji/terrors"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want terrors.Type
+	}{
+		{"pgx unique", &pgconn.PgError{Code: "23505", Message: "duplicate key"}, terrors.TypeExist},
+		{"pgx class", &pgconn.PgError{Code: "08006", Message: "connection failure"}, terrors.TypeUnavailable},
+		{"pgx wrapped", fmt.Errorf("insert user: %w", &pgconn.PgError{Code: "40P01"}), terrors.TypeConflict},
+		{"pq unique", &pq.Error{Code: "23505", Message: "duplicate key"}, terrors.TypeExist},
+		{"pq class", &pq.Error{Code: "22001", Message: "value too long"}, terrors.TypeInvalid},
+		{"pq wrapped", fmt.Errorf("update: %w", &pq.Error{Code: "42501"}), terrors.TypePermission},
+		{"no rows", fmt.Errorf("find: %w", sql.ErrNoRows), terrors.TypeNotExist},
+		{"conn done", sql.ErrConnDone, terrors.TypeUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Classify(tt.err)
+			if terrors.TypeOf(got) != tt.want {
+				t.Errorf("TypeOf() = %v, want %v", terrors.TypeOf(got), tt.want)
+			}
+			if terrors.Cause(got) != terrors.Cause(tt.err) || got.Error() != tt.err.Error() {
+				t.Errorf("Classify() lost the original error: %v", got)
+			}
+		})
+	}
+}
+
+func TestClassifyUnchanged(t *testing.T) {
+	unknown := &pgconn.PgError{Code: "P0001", Message: "raise exception"}
+	typed := terrors.Wrap(terrors.TypeInvalid, &pgconn.PgError{Code: "23505"}, "insert")
+
+	for _, err := range []error{nil, unknown, typed, fmt.Errorf("plain")} {
+		if got := Classify(err); got != err {
+			t.Errorf("Classify(%v) = %v, want it unchanged", err, got)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	defer func() {
+		mu.Lock()
+		delete(codes, "42")
+		delete(codes, "42P01")
+		codes["23505"] = terrors.TypeExist
+		mu.Unlock()
+	}()
+
+	undefinedTable := &pq.Error{Code: "42P01"}
+	undefinedColumn := &pgconn.PgError{Code: "42703"}
+	if _, ok := terrors.TypeOk(Classify(undefinedTable)); ok {
+		t.Fatal("42P01 is classified before registering")
+	}
+
+	Register("42", terrors.TypeInternal)
+	if got := terrors.TypeOf(Classify(undefinedColumn)); got != terrors.TypeInternal {
+		t.Errorf("class: TypeOf() = %v, want internal", got)
+	}
+
+	Register("42P01", terrors.TypeNotExist)
+	if got := terrors.TypeOf(Classify(undefinedTable)); got != terrors.TypeNotExist {
+		t.Errorf("code over class: TypeOf() = %v, want not_exist", got)
+	}
+	if got := terrors.TypeOf(Classify(undefinedColumn)); got != terrors.TypeInternal {
+		t.Errorf("other code of the class: TypeOf() = %v, want internal", got)
+	}
+
+	Register("23505", terrors.TypeConflict)
+	if got := terrors.TypeOf(Classify(&pgconn.PgError{Code: "23505"})); got != terrors.TypeConflict {
+		t.Errorf("overridden code: TypeOf() = %v, want conflict", got)
+	}
+}
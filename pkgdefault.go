@@ -0,0 +1,58 @@
+package terrors
+
+import (
+	"sort"
+	"sync"
+)
+
+type packageDefault struct {
+	prefix string
+	t      Type
+}
+
+var (
+	packageDefaultsMu sync.RWMutex
+	packageDefaults   []packageDefault // longest prefix first
+)
+
+// RegisterPackageDefault sets the type reported by TypeOf, OuterType and
+// RootType for errors without any typed layer whose origin, see Caller, is a
+// function of a package under pkgPathPrefix, such as "example.com/app/store".
+// The longest matching prefix wins; errors without a stack or without a
+// matching prefix get DefaultType. Registering a prefix again replaces its
+// type.
+func RegisterPackageDefault(pkgPathPrefix string, t Type) {
+	packageDefaultsMu.Lock()
+	defer packageDefaultsMu.Unlock()
+
+	for i, d := range packageDefaults {
+		if d.prefix == pkgPathPrefix {
+			packageDefaults[i].t = t
+			return
+		}
+	}
+	packageDefaults = append(packageDefaults, packageDefault{prefix: pkgPathPrefix, t: t})
+	sort.SliceStable(packageDefaults, func(i, j int) bool {
+		return len(packageDefaults[i].prefix) > len(packageDefaults[j].prefix)
+	})
+}
+
+// untypedType returns the type of an error without typed layers.
+func untypedType(err error) Type {
+	packageDefaultsMu.RLock()
+	defer packageDefaultsMu.RUnlock()
+
+	if len(packageDefaults) == 0 {
+		return DefaultType()
+	}
+	function, _, _, ok := Caller(err)
+	if !ok {
+		return DefaultType()
+	}
+	for _, d := range packageDefaults {
+		if inPackage(function, d.prefix) {
+			return d.t
+		}
+	}
+	return DefaultType()
+}
@@ -0,0 +1,67 @@
+package terrors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"testing"
+
+	"github.com/thamaji/terrors/internal/pkgtest/store"
+	"github.com/thamaji/terrors/internal/pkgtest/store/sql"
+	"github.com/thamaji/terrors/internal/pkgtest/storefront"
+	"github.com/thamaji/terrors/internal/pkgtest/validate"
+)
+
+func TestRegisterPackageDefault(t *testing.T) {
+	const pkg = "github.com/thamaji/terrors/internal/pkgtest/"
+	t.Cleanup(func() {
+		packageDefaultsMu.Lock()
+		packageDefaults = nil
+		packageDefaultsMu.Unlock()
+	})
+
+	tests := []struct {
+		name string
+		err  error
+		want Type
+	}{
+		{"package", store.Fail(), TypeUnknown},
+		{"longest prefix", sql.Fail(), TypeUnknown},
+		{"other package", validate.Fail(), TypeUnknown},
+		{"not a path prefix", storefront.Fail(), TypeUnknown},
+		{"wrapped by fmt", fmt.Errorf("save: %w", sql.Fail()), TypeUnknown},
+		{"no stack", stderrors.New("plain"), TypeUnknown},
+		{"typed", Wrap(TypeConflict, store.Fail(), "save"), TypeConflict},
+	}
+	check := func(stage string, want func(i int) Type) {
+		for i, tt := range tests {
+			if got := TypeOf(tt.err); got != want(i) {
+				t.Errorf("%s, %s: TypeOf() = %v, want %v", stage, tt.name, got, want(i))
+			}
+			if got := RootType(tt.err); got != want(i) {
+				t.Errorf("%s, %s: RootType() = %v, want %v", stage, tt.name, got, want(i))
+			}
+		}
+	}
+
+	// nothing registered: the default type
+	check("unregistered", func(i int) Type { return tests[i].want })
+
+	RegisterPackageDefault(pkg+"store/sql", TypeUnavailable)
+	RegisterPackageDefault(pkg+"store", TypeInternal)
+	RegisterPackageDefault(pkg+"validate", TypeInvalid)
+	registered := []Type{
+		TypeInternal,
+		TypeUnavailable,
+		TypeInvalid,
+		TypeUnknown,
+		TypeUnavailable,
+		TypeUnknown,
+		TypeConflict,
+	}
+	check("registered", func(i int) Type { return registered[i] })
+
+	// registering again replaces the type
+	RegisterPackageDefault(pkg+"store", TypeTimeout)
+	registered[0] = TypeTimeout
+	check("replaced", func(i int) Type { return registered[i] })
+}
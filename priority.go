@@ -0,0 +1,76 @@
+package terrors
+
+import (
+	"sync"
+)
+
+var (
+	priorityMu   sync.RWMutex
+	typePriority = priorityRanks([]Type{
+		TypeInternal,
+		TypeUnavailable,
+		TypeTimeout,
+		TypeConflict,
+		TypeExist,
+		TypePermission,
+		TypeUnauthorized,
+		TypeNotExist,
+		TypeInvalid,
+		TypeCanceled,
+		TypeUnknown,
+		TypeNotError,
+	})
+)
+
+// SetTypePriority replaces the order in which TypeOf picks a type among the
+// errors of a join (Unwrap() []error), highest first. The default is
+//
+//	Internal > Unavailable > Timeout > Conflict > Exist > Permission >
+//	Unauthorized > NotExist > Invalid > Canceled > Unknown > NotError
+//
+// Types missing from priority rank below the listed ones, in the order of
+// their values, so the result never depends on the order of the errors.
+func SetTypePriority(priority []Type) {
+	ranks := priorityRanks(priority)
+
+	priorityMu.Lock()
+	typePriority = ranks
+	priorityMu.Unlock()
+}
+
+func priorityRanks(priority []Type) map[Type]int {
+	ranks := make(map[Type]int, len(priority))
+	for i, t := range priority {
+		if _, ok := ranks[t]; !ok {
+			ranks[t] = i
+		}
+	}
+	return ranks
+}
+
+// joinedType returns the type of highest priority among the errors of a
+// join, each typed as by TypeOk.
+func joinedType(errs []error) (Type, bool) {
+	priorityMu.RLock()
+	ranks := typePriority
+	priorityMu.RUnlock()
+
+	rank := func(t Type) int {
+		if r, ok := ranks[t]; ok {
+			return r
+		}
+		return len(ranks)
+	}
+
+	best, found := TypeUnknown, false
+	for _, err := range errs {
+		t, ok := TypeOk(err)
+		if !ok {
+			continue
+		}
+		if !found || rank(t) < rank(best) || rank(t) == rank(best) && t < best {
+			best, found = t, true
+		}
+	}
+	return best, found
+}
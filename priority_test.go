@@ -0,0 +1,100 @@
+package terrors
+
+import (
+	stderrors "errors"
+	"math/rand"
+	"testing"
+)
+
+// restorePriority puts the default type priority back after t.
+func restorePriority(t *testing.T) {
+	priorityMu.RLock()
+	saved := typePriority
+	priorityMu.RUnlock()
+	t.Cleanup(func() {
+		priorityMu.Lock()
+		typePriority = saved
+		priorityMu.Unlock()
+	})
+}
+
+// shuffled returns a join of an error of each of types, plus an untyped
+// error, in an order drawn from r.
+func shuffled(r *rand.Rand, types []Type) error {
+	errs := []error{stderrors.New("untyped")}
+	for _, t := range types {
+		errs = append(errs, New(t, t.String()))
+	}
+	r.Shuffle(len(errs), func(i, j int) { errs[i], errs[j] = errs[j], errs[i] })
+	return stderrors.Join(errs...)
+}
+
+func TestTypePriority(t *testing.T) {
+	tests := []struct {
+		types []Type
+		want  Type
+	}{
+		{[]Type{TypeInvalid, TypeInternal}, TypeInternal},
+		{[]Type{TypeInvalid, TypeNotExist, TypeUnauthorized}, TypeUnauthorized},
+		{[]Type{TypeTimeout, TypeUnavailable, TypeConflict}, TypeUnavailable},
+		{[]Type{TypeExist, TypePermission, TypeCanceled}, TypeExist},
+		{[]Type{TypeUnknown, TypeCanceled, TypeInvalid}, TypeInvalid},
+		{[]Type{TypeNotError, TypeUnknown}, TypeUnknown},
+		{[]Type{TypeInvalid, TypeInvalid, TypeTimeout, TypeTimeout}, TypeTimeout},
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for _, tt := range tests {
+		for i := 0; i < 50; i++ {
+			err := shuffled(r, tt.types)
+			if got := TypeOf(err); got != tt.want {
+				t.Fatalf("TypeOf(%q) = %v, want %v", err, got, tt.want)
+			}
+			if got, want := HTTPStatus(err), HTTPStatus(New(tt.want, "")); got != want {
+				t.Fatalf("HTTPStatus(%q) = %d, want %d", err, got, want)
+			}
+		}
+	}
+}
+
+func TestTypePriorityNested(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for i := 0; i < 50; i++ {
+		inner := shuffled(r, []Type{TypeInvalid, TypeConflict})
+		errs := []error{inner, New(TypeNotExist, "missing"), Wrap(TypeTimeout, stderrors.New("slow"), "call")}
+		r.Shuffle(len(errs), func(i, j int) { errs[i], errs[j] = errs[j], errs[i] })
+		err := stderrors.Join(errs...)
+		if got := TypeOf(err); got != TypeTimeout {
+			t.Fatalf("TypeOf(%q) = %v, want timeout", err, got)
+		}
+		if got := TypeOf(Wrap(TypeUnknown, err, "")); got != TypeUnknown {
+			t.Fatalf("a typed layer over the join: TypeOf() = %v, want unknown", got)
+		}
+	}
+}
+
+func TestSetTypePriority(t *testing.T) {
+	restorePriority(t)
+	SetTypePriority([]Type{TypeInvalid, TypeNotExist})
+
+	tests := []struct {
+		types []Type
+		want  Type
+	}{
+		{[]Type{TypeInternal, TypeInvalid}, TypeInvalid},
+		{[]Type{TypeInternal, TypeNotExist, TypeUnavailable}, TypeNotExist},
+		// the unlisted types rank by value: Internal before Unavailable
+		{[]Type{TypeUnavailable, TypeInternal}, TypeInternal},
+		{[]Type{TypeTimeout, TypeUnavailable, TypeConflict}, TypeConflict},
+	}
+
+	r := rand.New(rand.NewSource(3))
+	for _, tt := range tests {
+		for i := 0; i < 50; i++ {
+			err := shuffled(r, tt.types)
+			if got := TypeOf(err); got != tt.want {
+				t.Fatalf("TypeOf(%q) = %v, want %v", err, got, tt.want)
+			}
+		}
+	}
+}
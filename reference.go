@@ -0,0 +1,86 @@
+package terrors
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const referenceAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+var referenceNow atomic.Pointer[func() time.Time]
+
+// SetReferenceClock replaces the clock used by Reference. A nil clock
+// restores time.Now.
+func SetReferenceClock(now func() time.Time) {
+	if now == nil {
+		referenceNow.Store(nil)
+		return
+	}
+	referenceNow.Store(&now)
+}
+
+// Reference returns a code such as "AB12-CD34" that can be shown to end users
+// and given to support. The first half is the start of Fingerprint(err), the
+// second half the hour the reference was made, so it identifies the kind of
+// error and roughly when it happened without revealing anything else. The
+// format is stable; see ParseReference. A nil error yields "".
+func Reference(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	fp, _ := strconv.ParseUint(Fingerprint(err)[:5], 16, 32)
+	now := time.Now
+	if p := referenceNow.Load(); p != nil {
+		now = *p
+	}
+	hour := uint64(now().Unix()/3600) & (1<<20 - 1)
+
+	var b [9]byte
+	encodeReference(b[:4], fp)
+	b[4] = '-'
+	encodeReference(b[5:], hour)
+	return string(b[:])
+}
+
+// ParseReference decodes a reference made by Reference into the first five
+// hex digits of the fingerprint and the start of the hour it was made in,
+// in UTC. Lower case is accepted. The hour is kept modulo 2^20, about 119
+// years, so references made after 2089 decode to a time 119 years early.
+func ParseReference(ref string) (fingerprintPrefix string, t time.Time, err error) {
+	upper := strings.ToUpper(ref)
+	if len(upper) != 9 || upper[4] != '-' {
+		return "", time.Time{}, Errorf(TypeInvalid, "terrors: malformed reference %q", ref)
+	}
+
+	fp, ok := decodeReference(upper[:4])
+	hour, ok2 := decodeReference(upper[5:])
+	if !ok || !ok2 {
+		return "", time.Time{}, Errorf(TypeInvalid, "terrors: malformed reference %q", ref)
+	}
+
+	prefix := strconv.FormatUint(fp, 16)
+	prefix = strings.Repeat("0", 5-len(prefix)) + prefix
+	return prefix, time.Unix(int64(hour)*3600, 0).UTC(), nil
+}
+
+func encodeReference(b []byte, v uint64) {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i] = referenceAlphabet[v&31]
+		v >>= 5
+	}
+}
+
+func decodeReference(s string) (uint64, bool) {
+	var v uint64
+	for i := 0; i < len(s); i++ {
+		d := strings.IndexByte(referenceAlphabet, s[i])
+		if d < 0 {
+			return 0, false
+		}
+		v = v<<5 | uint64(d)
+	}
+	return v, true
+}
@@ -0,0 +1,68 @@
+package terrors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReferenceGolden(t *testing.T) {
+	t.Cleanup(func() { SetReferenceClock(nil) })
+
+	// stackless errors, so that fingerprints do not depend on line numbers
+	errs := []error{
+		stderrors.New("boom"),
+		stderrors.New("connection refused"),
+		WithMessage(TypeNotExist, stderrors.New("boom"), "lookup"),
+	}
+	times := []time.Time{
+		time.Unix(0, 0),
+		time.Date(2024, 1, 1, 0, 59, 59, 0, time.UTC),
+		time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC),
+		time.Date(2100, 6, 15, 12, 30, 0, 0, time.UTC),
+	}
+
+	var b strings.Builder
+	for _, err := range errs {
+		for _, at := range times {
+			SetReferenceClock(func() time.Time { return at })
+			ref := Reference(err)
+			prefix, hour, perr := ParseReference(ref)
+			fmt.Fprintf(&b, "%-31q %s  %s  %s %s %v\n", TypeOf(err).String()+": "+err.Error(), at.UTC().Format(time.RFC3339), ref, prefix, hour.Format(time.RFC3339), perr)
+		}
+	}
+	golden(t, "reference", b.String())
+}
+
+func TestReference(t *testing.T) {
+	at := time.Date(2024, 3, 10, 17, 45, 0, 0, time.UTC)
+	SetReferenceClock(func() time.Time { return at })
+	t.Cleanup(func() { SetReferenceClock(nil) })
+
+	if got := Reference(nil); got != "" {
+		t.Errorf("Reference(nil) = %q", got)
+	}
+
+	err := New(TypeInternal, "boom")
+	ref := Reference(err)
+	prefix, hour, perr := ParseReference(strings.ToLower(ref))
+	if perr != nil {
+		t.Fatalf("ParseReference(%q): %v", ref, perr)
+	}
+	if want := Fingerprint(err)[:5]; prefix != want {
+		t.Errorf("prefix = %q, want %q", prefix, want)
+	}
+	if want := at.Truncate(time.Hour); !hour.Equal(want) {
+		t.Errorf("hour = %v, want %v", hour, want)
+	}
+}
+
+func TestParseReferenceMalformed(t *testing.T) {
+	for _, ref := range []string{"", "AB12CD34", "AB12-CD3", "AB12_CD34", "AB1I-CD34", "AB12-CD3U", "AB12-CD34-"} {
+		if _, _, err := ParseReference(ref); TypeOf(err) != TypeInvalid {
+			t.Errorf("ParseReference(%q) = %v, want TypeInvalid", ref, err)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package terrors
+
+import (
+	"sync"
+)
+
+var (
+	registryMu sync.RWMutex
+	registered []string // names of the types after TypeUnavailable
+)
+
+// RegisterType defines a type named name, after the types of this package,
+// and returns it. It is meant to be called at init time; registering a name
+// again returns the same type. The names of the types of this package and
+// the empty name are rejected with a TypeInvalid error.
+func RegisterType(name string) (Type, error) {
+	if name == "" {
+		return TypeUnknown, New(TypeInvalid, "terrors: empty type name")
+	}
+	for t := TypeUnknown; t <= TypeUnavailable; t++ {
+		if t.String() == name {
+			return TypeUnknown, Errorf(TypeInvalid, "terrors: type %q is predefined", name)
+		}
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for i, n := range registered {
+		if n == name {
+			return TypeUnavailable + 1 + Type(i), nil
+		}
+	}
+	registered = append(registered, name)
+	return TypeUnavailable + Type(len(registered)), nil
+}
+
+// ListTypes returns every type, those of this package first, then the
+// registered ones in the order they were registered.
+func ListTypes() []Type {
+	registryMu.RLock()
+	n := len(registered)
+	registryMu.RUnlock()
+
+	types := make([]Type, 0, int(TypeUnavailable)+1+n)
+	for t := TypeUnknown; t <= TypeUnavailable+Type(n); t++ {
+		types = append(types, t)
+	}
+	return types
+}
+
+// TypeName is the same as t.String().
+func TypeName(t Type) string {
+	return t.String()
+}
+
+// TypeByName is the same as ParseType.
+func TypeByName(name string) (Type, bool) {
+	return ParseType(name)
+}
+
+// IsRegistered reports whether t is a type of this package or one returned
+// by RegisterType.
+func IsRegistered(t Type) bool {
+	if t >= TypeUnknown && t <= TypeUnavailable {
+		return true
+	}
+
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return t > TypeUnavailable && int(t-TypeUnavailable) <= len(registered)
+}
+
+func registeredName(t Type) (string, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	i := int(t - TypeUnavailable - 1)
+	if t <= TypeUnavailable || i >= len(registered) {
+		return "", false
+	}
+	return registered[i], true
+}
+
+func registeredType(name string) (Type, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	for i, n := range registered {
+		if n == name {
+			return TypeUnavailable + 1 + Type(i), true
+		}
+	}
+	return TypeUnknown, false
+}
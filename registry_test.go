@@ -0,0 +1,93 @@
+package terrors
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestListTypes(t *testing.T) {
+	quota, err := RegisterType("test_quota_exceeded")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := RegisterType("test_quota_exceeded")
+	if err != nil || again != quota {
+		t.Fatalf("registering again = %v, %v, want %v", again, err, quota)
+	}
+	throttled, err := RegisterType("test_throttled")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	types := ListTypes()
+	for builtin := TypeUnknown; builtin <= TypeUnavailable; builtin++ {
+		if types[builtin] != builtin {
+			t.Fatalf("ListTypes() = %v, want the types of this package first", types)
+		}
+	}
+
+	count := map[Type]int{}
+	for i, typ := range types {
+		count[typ]++
+		if i > 0 && typ <= types[i-1] {
+			t.Errorf("ListTypes() is not in order: %v", types)
+		}
+		if !IsRegistered(typ) {
+			t.Errorf("IsRegistered(%v) = false", typ)
+		}
+		if got, ok := TypeByName(TypeName(typ)); !ok || got != typ || TypeName(typ) != typ.String() {
+			t.Errorf("TypeByName(TypeName(%d)) = %v, %v", typ, got, ok)
+		}
+	}
+	if count[quota] != 1 || count[throttled] != 1 {
+		t.Errorf("registered types listed %d and %d times, want once", count[quota], count[throttled])
+	}
+
+	last := types[len(types)-1]
+	if IsRegistered(last+1) || IsRegistered(TypeUnknown-1) {
+		t.Errorf("IsRegistered() of unknown values = true")
+	}
+}
+
+func TestRegisterTypeInvalid(t *testing.T) {
+	for _, name := range []string{"", "internal", "not_exist"} {
+		if _, err := RegisterType(name); TypeOf(err) != TypeInvalid {
+			t.Errorf("RegisterType(%q) = %v, want TypeInvalid", name, err)
+		}
+	}
+	if _, ok := TypeByName("test_never_registered"); ok {
+		t.Error("TypeByName() of an unregistered name = true")
+	}
+}
+
+func TestRegistryConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := RegisterType("test_concurrent"); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for _, typ := range ListTypes() {
+				if _, ok := TypeByName(typ.String()); !ok {
+					t.Errorf("TypeByName(%q) = false", typ)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	n := 0
+	for _, typ := range ListTypes() {
+		if typ.String() == "test_concurrent" {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Errorf("test_concurrent listed %d times, want once", n)
+	}
+}
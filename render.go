@@ -0,0 +1,121 @@
+package terrors
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Render formats err for terminal output: the outermost message first, each
+// deeper annotation on its own indented line, and the innermost stack when
+// verbose is set. The errors of a join (Unwrap() []error) are rendered one
+// after the other at the depth of the join, as Tree does. The result never
+// ends with a newline.
+func Render(err error, verbose bool) string {
+	if err == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	renderChain(&b, err, 0)
+
+	if verbose {
+		for _, f := range resolveFrames(innermostStack(err)) {
+			if f.File == "" {
+				fmt.Fprintf(&b, "\n    %s", f.Function)
+				continue
+			}
+			fmt.Fprintf(&b, "\n    at %s (%s:%d)", f.Function, trimPath(f.File), f.Line)
+		}
+	}
+
+	return b.String()
+}
+
+// renderChain writes a line for every layer of err's chain contributing a
+// message, starting at depth.
+func renderChain(b *strings.Builder, err error, depth int) {
+	for e := err; e != nil; e = unwrapOnce(e) {
+		if joined, ok := e.(interface{ Unwrap() []error }); ok && unwrapOnce(e) == nil {
+			for _, child := range joined.Unwrap() {
+				if child != nil {
+					renderChain(b, child, depth)
+				}
+			}
+			return
+		}
+
+		msg, split := ownMessage(e)
+		if msg != "" {
+			if b.Len() > 0 {
+				b.WriteByte('\n')
+			}
+			if depth > 0 {
+				b.WriteString(strings.Repeat("  ", depth))
+				b.WriteString("↳ ")
+			}
+			b.WriteString(msg)
+			depth++
+		}
+		if !split {
+			return
+		}
+	}
+}
+
+// Short formats err on two lines for logs where %+v is too verbose: the
+// message and the origin of err, see Caller:
+//
+//	load config: open app.yaml: no such file or directory
+//	    at config.Load (config/load.go:42)
+//
+// An error without a stack yields its message alone.
+func Short(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	function, file, line, ok := Caller(err)
+	if !ok {
+		return err.Error()
+	}
+	if i := strings.LastIndexByte(function, '/'); i >= 0 {
+		function = function[i+1:]
+	}
+	return fmt.Sprintf("%s\n    at %s (%s:%d)", err.Error(), function, trimPath(file), line)
+}
+
+// ownMessage returns the part of err's message contributed by err itself.
+// split is false when the message could not be separated from its cause's,
+// in which case msg holds the whole message and deeper layers should not be
+// printed again.
+func ownMessage(err error) (msg string, split bool) {
+	switch e := err.(type) {
+	case *fundamental:
+		return e.msg, true
+	case *withStack:
+		return "", true
+	case *withMessage:
+		return e.msg, true
+	case *wrapped:
+		return e.msg, true
+	case *withPlainMessage:
+		return e.msg, true
+	case *withCause:
+		return e.msg, true
+	}
+
+	msg = err.Error()
+	cause := unwrapOnce(err)
+	if cause == nil {
+		return msg, true
+	}
+
+	causeMsg := cause.Error()
+	if msg == causeMsg {
+		return "", true
+	}
+	if strings.HasSuffix(msg, ": "+causeMsg) {
+		return strings.TrimSuffix(msg, ": "+causeMsg), true
+	}
+	return msg, false
+}
@@ -0,0 +1,55 @@
+package terrors
+
+import (
+	"errors"
+	"testing"
+)
+
+func loadConfig() error {
+	return Wrap(TypeNotExist, openFile(), "load config")
+}
+
+func openFile() error {
+	return New(TypeNotExist, "open app.yaml: no such file or directory")
+}
+
+func TestRender(t *testing.T) {
+	appFrames(t)
+
+	err := loadConfig()
+	golden(t, "render", Render(err, false))
+	golden(t, "render_verbose", Render(err, true))
+}
+
+func TestRenderJoin(t *testing.T) {
+	err := Wrap(TypeInternal, errors.Join(loadConfig(), Wrap(TypeTimeout, errors.New("dial: EOF"), "fetch user 2")), "batch")
+	top := errors.Join(New(TypeInvalid, "bad name"), New(TypeInvalid, "bad age"))
+	golden(t, "render_join", Render(err, false)+"\n"+Render(top, false)+"\n")
+}
+
+func TestRenderForeignCause(t *testing.T) {
+	err := Wrap(TypeInternal, errors.New("connection reset"), "query users")
+
+	want := "query users\n  ↳ connection reset"
+	if got := Render(err, false); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestRenderNil(t *testing.T) {
+	if got := Render(nil, true); got != "" {
+		t.Errorf("Render(nil) = %q, want empty", got)
+	}
+}
+
+func TestShort(t *testing.T) {
+	err := loadConfig()
+	golden(t, "short", Short(err)+"\n"+Short(WithOp(Wrap(TypeInternal, err, "start"), "main"))+"\n")
+
+	if got := Short(errors.New("connection reset")); got != "connection reset" {
+		t.Errorf("Short() without a stack = %q", got)
+	}
+	if got := Short(nil); got != "" {
+		t.Errorf("Short(nil) = %q, want empty", got)
+	}
+}
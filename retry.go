@@ -0,0 +1,164 @@
+package terrors
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RetryAfter asks Retry to wait at least this long before the next attempt.
+// Attach it with WithDetails.
+type RetryAfter time.Duration
+
+type RetryPolicy struct {
+	// MaxAttempts is the number of calls made at most, 3 when not positive.
+	MaxAttempts int
+	// Backoff returns the delay after the given failed attempt, starting at
+	// 1. It defaults to ExponentialBackoff(100ms, 10s).
+	Backoff func(attempt int) time.Duration
+	// Retryable reports whether an error is worth another attempt. It
+	// defaults to IsRetryable.
+	Retryable func(err error) bool
+	// Sleep waits for d or until ctx is done. Tests can replace it with a
+	// fake clock.
+	Sleep func(ctx context.Context, d time.Duration) error
+}
+
+func FixedBackoff(d time.Duration) func(attempt int) time.Duration {
+	return func(int) time.Duration {
+		return d
+	}
+}
+
+// ExponentialBackoff doubles the delay from base after every attempt, up to
+// max, and picks a random delay between zero and that value.
+func ExponentialBackoff(base time.Duration, max time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		d := base
+		for i := 1; i < attempt && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		if d <= 0 {
+			return 0
+		}
+		return time.Duration(rand.Int63n(int64(d) + 1))
+	}
+}
+
+// IsRetryable reports whether err is of a transient type: TypeConflict,
+// TypeTimeout or TypeUnavailable.
+func IsRetryable(err error) bool {
+	switch TypeOf(err) {
+	case TypeConflict, TypeTimeout, TypeUnavailable:
+		return true
+	}
+	return false
+}
+
+// RetryTypes returns a RetryPolicy.Retryable retrying errors of the given
+// types.
+func RetryTypes(types ...Type) func(err error) bool {
+	return func(err error) bool {
+		t := TypeOf(err)
+		for _, retryable := range types {
+			if t == retryable {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Retry calls fn until it succeeds, fails with an error that is not
+// retryable, or policy.MaxAttempts is reached; the last error is then
+// returned annotated with the number of attempts, keeping its type. The
+// delay before the next attempt is the longer of the backoff and the
+// RetryAfter detail of the error. When ctx is done Retry stops and returns
+// its error typed as TypeCanceled or TypeTimeout.
+func Retry(ctx context.Context, policy RetryPolicy, fn func() error) error {
+	stack := errors.New("").(StackTracer).StackTrace()[1:]
+
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 3
+	}
+	backoff := policy.Backoff
+	if backoff == nil {
+		backoff = ExponentialBackoff(100*time.Millisecond, 10*time.Second)
+	}
+	retryable := policy.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+	sleep := policy.Sleep
+	if sleep == nil {
+		sleep = sleepContext
+	}
+
+	for attempt := 1; ; attempt++ {
+		if ctx.Err() != nil {
+			return contextError(ctx.Err(), stack)
+		}
+
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if !retryable(err) {
+			if attempt == 1 {
+				return err
+			}
+			return newWrapped(TypeOf(err), err, attempts(attempt), stack)
+		}
+		if attempt >= maxAttempts {
+			return newWrapped(TypeOf(err), err, attempts(attempt), stack)
+		}
+
+		d := backoff(attempt)
+		if after, ok := Details[RetryAfter](err); ok && time.Duration(after) > d {
+			d = time.Duration(after)
+		}
+		if err := sleep(ctx, d); err != nil {
+			return contextError(err, stack)
+		}
+	}
+}
+
+func attempts(n int) string {
+	if n == 1 {
+		return "after 1 attempt"
+	}
+	return fmt.Sprintf("after %d attempts", n)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// contextError types a context error: TypeTimeout for deadlines, TypeCanceled
+// otherwise.
+func contextError(err error, stack errors.StackTrace) error {
+	t := TypeCanceled
+	if errors.Is(err, context.DeadlineExceeded) {
+		t = TypeTimeout
+	}
+	return newWithStack(t, err, stack)
+}
@@ -0,0 +1,139 @@
+package terrors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// fakeSleep records the delays Retry waits for without waiting.
+type fakeSleep struct {
+	delays []time.Duration
+	cancel context.CancelFunc // called on the given call, when set
+	at     int
+}
+
+func (f *fakeSleep) sleep(ctx context.Context, d time.Duration) error {
+	f.delays = append(f.delays, d)
+	if f.cancel != nil && len(f.delays) == f.at {
+		f.cancel()
+	}
+	return ctx.Err()
+}
+
+func TestRetryAttempts(t *testing.T) {
+	down := New(TypeUnavailable, "down")
+	slow := New(TypeTimeout, "slow")
+	bad := New(TypeInvalid, "bad")
+
+	tests := []struct {
+		name  string
+		errs  []error // returned by the calls in turn, then nil
+		calls int
+		want  string
+		typ   Type
+	}{
+		{"success", nil, 1, "", TypeNotError},
+		{"success after retries", []error{down, down}, 3, "", TypeNotError},
+		{"exhausted", []error{slow, slow, slow, slow, slow}, 4, "after 4 attempts: slow", TypeTimeout},
+		{"not retryable", []error{bad}, 1, "bad", TypeInvalid},
+		{"not retryable later", []error{down, bad}, 2, "after 2 attempts: bad", TypeInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeSleep{}
+			calls := 0
+			err := Retry(context.Background(), RetryPolicy{MaxAttempts: 4, Backoff: FixedBackoff(time.Second), Sleep: fake.sleep}, func() error {
+				calls++
+				if calls > len(tt.errs) {
+					return nil
+				}
+				return tt.errs[calls-1]
+			})
+
+			if calls != tt.calls {
+				t.Errorf("%d calls, want %d", calls, tt.calls)
+			}
+			if len(fake.delays) != tt.calls-1 {
+				t.Errorf("%d sleeps, want %d", len(fake.delays), tt.calls-1)
+			}
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Retry() = %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.want || TypeOf(err) != tt.typ {
+				t.Errorf("Retry() = %v (%v), want %q (%v)", err, TypeOf(err), tt.want, tt.typ)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	fake := &fakeSleep{}
+	calls := 0
+	err := Retry(context.Background(), RetryPolicy{Backoff: FixedBackoff(time.Second), Sleep: fake.sleep}, func() error {
+		calls++
+		switch calls {
+		case 1:
+			return WithDetails(New(TypeUnavailable, "throttled"), RetryAfter(5*time.Second))
+		case 2:
+			return WithDetails(New(TypeUnavailable, "throttled"), RetryAfter(time.Millisecond))
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(fake.delays) != "[5s 1s]" {
+		t.Errorf("delays = %v, want the longer of RetryAfter and the backoff", fake.delays)
+	}
+}
+
+func TestRetryContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	fake := &fakeSleep{cancel: cancel, at: 2}
+	calls := 0
+	err := Retry(ctx, RetryPolicy{MaxAttempts: 10, Sleep: fake.sleep}, func() error {
+		calls++
+		return New(TypeUnavailable, "down")
+	})
+
+	if calls != 2 || TypeOf(err) != TypeCanceled || !stderrors.Is(err, context.Canceled) {
+		t.Errorf("%d calls, Retry() = %v (%v)", calls, err, TypeOf(err))
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+	calls = 0
+	err = Retry(ctx, RetryPolicy{}, func() error { calls++; return nil })
+	if calls != 0 || TypeOf(err) != TypeTimeout || !stderrors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("%d calls, Retry() = %v (%v)", calls, err, TypeOf(err))
+	}
+}
+
+func TestRetryTypes(t *testing.T) {
+	retryable := RetryTypes(TypeNotExist)
+	if !retryable(Wrap(TypeNotExist, New(TypeInvalid, "x"), "y")) || retryable(New(TypeUnavailable, "down")) {
+		t.Error("RetryTypes() does not follow TypeOf")
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(100*time.Millisecond, time.Second)
+	for attempt, max := range []time.Duration{0, 100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		if attempt == 0 {
+			continue
+		}
+		for i := 0; i < 20; i++ {
+			if d := backoff(attempt); d < 0 || d > max {
+				t.Errorf("backoff(%d) = %v, want at most %v", attempt, d, max)
+			}
+		}
+	}
+}
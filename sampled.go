@@ -0,0 +1,36 @@
+package terrors
+
+import (
+	"math/rand"
+
+	"github.com/pkg/errors"
+)
+
+// StackSampling records the sampling decision of NewSampled: the rate and
+// whether the stack was captured. Counts of sampled errors can be scaled by
+// 1/Rate.
+type StackSampling struct {
+	Rate     float64
+	Captured bool
+}
+
+// NewSampled is like New but captures the stack only for a fraction rate of
+// the errors, for error paths too hot to pay for a stack every time. Errors
+// without a stack format and encode like any other; the decision is
+// recorded, see StackSampled.
+func NewSampled(t Type, msg string, rate float64) error {
+	f := newFundamental(t, msg, nil)
+
+	captured := rate >= 1 || rate > 0 && rand.Float64() < rate
+	if captured {
+		f.stack = errors.New(msg).(StackTracer).StackTrace()[1:]
+	}
+
+	return &withDetails{cause: f, details: StackSampling{Rate: rate, Captured: captured}}
+}
+
+// StackSampled returns the sampling decision of an error created by
+// NewSampled.
+func StackSampled(err error) (StackSampling, bool) {
+	return Details[StackSampling](err)
+}
@@ -0,0 +1,85 @@
+package terrors
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNewSampled(t *testing.T) {
+	tests := []struct {
+		rate     float64
+		captured bool
+	}{
+		{0, false},
+		{-1, false},
+		{1, true},
+		{2, true},
+	}
+	for _, tt := range tests {
+		err := NewSampled(TypeUnavailable, "throttled", tt.rate)
+		s, ok := StackSampled(err)
+		if !ok || s.Rate != tt.rate || s.Captured != tt.captured {
+			t.Errorf("rate %v: StackSampled() = %+v, %v", tt.rate, s, ok)
+		}
+		if st, _ := StackTrace(err); len(st) > 0 != tt.captured {
+			t.Errorf("rate %v: has a stack = %v, want %v", tt.rate, len(st) > 0, tt.captured)
+		}
+		if err.Error() != "throttled" || TypeOf(err) != TypeUnavailable {
+			t.Errorf("rate %v: NewSampled() = %v: %q", tt.rate, TypeOf(err), err)
+		}
+	}
+
+	if _, ok := StackSampled(New(TypeInternal, "x")); ok {
+		t.Error("StackSampled() of New = true")
+	}
+}
+
+func TestNewSampledRate(t *testing.T) {
+	captured := 0
+	for i := 0; i < 10000; i++ {
+		if s, _ := StackSampled(NewSampled(TypeInternal, "x", 0.1)); s.Captured {
+			captured++
+		}
+	}
+	if captured < 800 || captured > 1200 {
+		t.Errorf("%d of 10000 stacks captured at rate 0.1", captured)
+	}
+}
+
+func TestNewSampledWithoutStack(t *testing.T) {
+	err := Wrap(TypeInternal, NewSampled(TypeUnavailable, "throttled", 0), "call")
+
+	if got := fmt.Sprintf("%+v", err); !strings.HasPrefix(got, "call\n") || !strings.Contains(got, "\ncaused by: throttled\n") {
+		t.Errorf("%%+v = %q", got)
+	}
+	// the origin is the wrapping, the innermost layer with a stack
+	if function, _, _, _ := Caller(err); function != "github.com/thamaji/terrors.TestNewSampledWithoutStack" {
+		t.Errorf("Caller() = %s", function)
+	}
+	if got := Short(NewSampled(TypeUnavailable, "throttled", 0)); got != "throttled" {
+		t.Errorf("Short() = %q", got)
+	}
+
+	m := Encode(err, true)
+	if _, err := json.Marshal(m); err != nil {
+		t.Fatal(err)
+	}
+	decoded, derr := Decode(m)
+	if derr != nil || decoded.Error() != err.Error() || RootType(decoded) != TypeUnavailable {
+		t.Errorf("Decode() = %v, %v", decoded, derr)
+	}
+}
+
+func BenchmarkNewSampled(b *testing.B) {
+	for _, rate := range []float64{0.001, 1} {
+		b.Run(fmt.Sprint("rate=", rate), func(b *testing.B) {
+			fn := func() error { return NewSampled(TypeInvalid, "x", rate) }
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = deep(20, fn)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package terrors
+
+import (
+	"fmt"
+	"io"
+)
+
+// WithSecondary attaches secondary to primary, typically an error returned
+// by a rollback or Close after primary occurred. The result behaves like
+// primary for Error, TypeOf and errors.Is; the secondary errors are only
+// reachable with Secondary, shown by %+v after "additionally:" and kept by
+// Encode and AppendBinary. Attaching to an error that already has secondary
+// errors adds to them. When primary is nil, secondary is returned.
+func WithSecondary(primary, secondary error) error {
+	if secondary == nil {
+		return primary
+	}
+	if primary == nil {
+		return secondary
+	}
+
+	if w, ok := primary.(*withSecondary); ok {
+		errs := make([]error, 0, len(w.secondary)+1)
+		errs = append(append(errs, w.secondary...), secondary)
+		return &withSecondary{cause: w.cause, secondary: errs}
+	}
+	return &withSecondary{cause: primary, secondary: []error{secondary}}
+}
+
+// AppendSecondary sets *errp to WithSecondary(*errp, err), for deferred
+// cleanup in a function with a named error result:
+//
+//	defer func() { terrors.AppendSecondary(&err, f.Close()) }()
+//
+// The closure matters: a plain defer would call f.Close right away.
+func AppendSecondary(errp *error, err error) {
+	*errp = WithSecondary(*errp, err)
+}
+
+// Secondary returns the secondary errors attached to err's chain, outermost
+// first.
+func Secondary(err error) []error {
+	var errs []error
+	walk(err, func(e error) bool {
+		if w, ok := e.(*withSecondary); ok {
+			errs = append(errs, w.secondary...)
+		}
+		return true
+	})
+	return errs
+}
+
+type withSecondary struct {
+	cause     error
+	secondary []error
+}
+
+func (w *withSecondary) Error() string {
+	return w.cause.Error()
+}
+
+func (w *withSecondary) Cause() error {
+	return w.cause
+}
+
+func (w *withSecondary) Unwrap() error {
+	return w.cause
+}
+
+func (w *withSecondary) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			formatVerbose(s, w)
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, w.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", w.Error())
+	}
+}
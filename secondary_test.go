@@ -0,0 +1,84 @@
+package terrors
+
+import (
+	stderrors "errors"
+	"testing"
+)
+
+// closer fails its Close with err and records whether it was closed.
+type closer struct {
+	err    error
+	closed bool
+}
+
+func (c *closer) Close() error {
+	c.closed = true
+	return c.err
+}
+
+func process(c *closer, work error) (err error) {
+	defer func() { AppendSecondary(&err, c.Close()) }()
+
+	if c.closed {
+		return New(TypeInternal, "closed before use")
+	}
+	return work
+}
+
+func TestAppendSecondary(t *testing.T) {
+	closeErr := New(TypeUnavailable, "close: broken pipe")
+	workErr := New(TypeInvalid, "bad record")
+
+	tests := []struct {
+		name      string
+		work      error
+		close     error
+		msg       string
+		secondary []error
+	}{
+		{"both succeed", nil, nil, "", nil},
+		{"work fails", workErr, nil, "bad record", nil},
+		{"close fails", nil, closeErr, "close: broken pipe", nil},
+		{"both fail", workErr, closeErr, "bad record", []error{closeErr}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &closer{err: tt.close}
+			err := process(c, tt.work)
+			if !c.closed {
+				t.Fatal("Close was not called")
+			}
+
+			if (err == nil) != (tt.msg == "") || err != nil && err.Error() != tt.msg {
+				t.Fatalf("process() = %v, want %q", err, tt.msg)
+			}
+			if tt.close != nil && tt.work == nil && err != tt.close {
+				t.Errorf("process() = %v, want the Close error itself", err)
+			}
+			if tt.work != nil && (TypeOf(err) != TypeInvalid || !stderrors.Is(err, tt.work)) {
+				t.Errorf("process() = %v (%v), want the work error", err, TypeOf(err))
+			}
+
+			secondary := Secondary(err)
+			if len(secondary) != len(tt.secondary) || len(secondary) > 0 && secondary[0] != tt.secondary[0] {
+				t.Errorf("Secondary() = %v, want %v", secondary, tt.secondary)
+			}
+		})
+	}
+}
+
+func TestAppendSecondaryAccumulates(t *testing.T) {
+	err := error(New(TypeInvalid, "bad record"))
+	first, second := stderrors.New("close a"), stderrors.New("close b")
+	AppendSecondary(&err, first)
+	AppendSecondary(&err, nil)
+	AppendSecondary(&err, second)
+
+	if secondary := Secondary(err); len(secondary) != 2 || secondary[0] != first || secondary[1] != second {
+		t.Errorf("Secondary() = %v", secondary)
+	}
+	if err.Error() != "bad record" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+}
@@ -0,0 +1,34 @@
+package terrors
+
+import (
+	"io/fs"
+	"sync/atomic"
+)
+
+var sentinelCompat atomic.Bool
+
+// EnableStdlibSentinelCompat makes errors.Is match the sentinels of io/fs
+// against the typed layers of this package: a layer of type TypeNotExist
+// matches fs.ErrNotExist, TypePermission fs.ErrPermission and TypeExist
+// fs.ErrExist, as do os.ErrNotExist and the others which are the same
+// values. Each layer matches by its own type. It is off by default since it
+// changes what errors.Is reports.
+func EnableStdlibSentinelCompat(enabled bool) {
+	sentinelCompat.Store(enabled)
+}
+
+func sentinelIs(t Type, target error) bool {
+	if !sentinelCompat.Load() {
+		return false
+	}
+
+	switch t {
+	case TypeNotExist:
+		return target == fs.ErrNotExist
+	case TypePermission:
+		return target == fs.ErrPermission
+	case TypeExist:
+		return target == fs.ErrExist
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package terrors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"testing"
+)
+
+func TestStdlibSentinelCompat(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		target error
+		compat bool // errors.Is with the compatibility enabled
+	}{
+		{"not exist", New(TypeNotExist, "missing"), fs.ErrNotExist, true},
+		{"os alias", New(TypeNotExist, "missing"), os.ErrNotExist, true},
+		{"permission", New(TypePermission, "denied"), fs.ErrPermission, true},
+		{"exist", New(TypeExist, "taken"), fs.ErrExist, true},
+		{"with stack", WithStack(TypeNotExist, io.EOF), fs.ErrNotExist, true},
+		{"with message", WithMessage(TypePermission, io.EOF, "open"), fs.ErrPermission, true},
+		{"wrapped", Wrap(TypeNotExist, io.EOF, "load"), fs.ErrNotExist, true},
+		{"inner layer", Wrap(TypeInternal, New(TypeNotExist, "missing"), "load"), fs.ErrNotExist, true},
+		{"through fmt", fmt.Errorf("load: %w", New(TypeExist, "taken")), fs.ErrExist, true},
+		{"other type", New(TypeInvalid, "bad"), fs.ErrNotExist, false},
+		{"other sentinel", New(TypeNotExist, "missing"), fs.ErrPermission, false},
+		{"not a sentinel", New(TypeNotExist, "missing"), io.EOF, false},
+		{"foreign", stderrors.New("missing"), fs.ErrNotExist, false},
+	}
+
+	t.Cleanup(func() { EnableStdlibSentinelCompat(false) })
+	for _, enabled := range []bool{false, true} {
+		EnableStdlibSentinelCompat(enabled)
+		for _, tt := range tests {
+			want := enabled && tt.compat
+			if got := stderrors.Is(tt.err, tt.target); got != want {
+				t.Errorf("compat %v, %s: errors.Is(%q, %v) = %v, want %v", enabled, tt.name, tt.err, tt.target, got, want)
+			}
+		}
+	}
+}
+
+func TestStdlibSentinelCause(t *testing.T) {
+	// a sentinel in the chain matches whether or not the compatibility is on
+	err := Wrap(TypeInternal, &fs.PathError{Op: "open", Path: "app.yaml", Err: fs.ErrNotExist}, "load")
+	t.Cleanup(func() { EnableStdlibSentinelCompat(false) })
+	for _, enabled := range []bool{false, true} {
+		EnableStdlibSentinelCompat(enabled)
+		if !stderrors.Is(err, fs.ErrNotExist) || !os.IsNotExist(Cause(err)) {
+			t.Errorf("compat %v: errors.Is(%q, fs.ErrNotExist) = false", enabled, err)
+		}
+	}
+}
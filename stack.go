@@ -0,0 +1,237 @@
+package terrors
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+func StackTrace(err error) (errors.StackTrace, bool) {
+	for err != nil {
+		if st, ok := err.(StackTracer); ok {
+			return st.StackTrace(), true
+		}
+		err = unwrapOnce(err)
+	}
+	return nil, false
+}
+
+func AllStacks(err error) []errors.StackTrace {
+	var stacks []errors.StackTrace
+	for err != nil {
+		if st, ok := err.(StackTracer); ok {
+			stacks = append(stacks, st.StackTrace())
+		}
+		err = unwrapOnce(err)
+	}
+	return stacks
+}
+
+type Frame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+func (f Frame) String() string {
+	if f.File == "" {
+		return f.Function
+	}
+	return f.Function + " " + f.Location()
+}
+
+// Location returns the file of f, trimmed to its directory, and its line, as
+// "store/db.go:42"; empty for the placeholder of dropped frames.
+func (f Frame) Location() string {
+	if f.File == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", trimPath(f.File), f.Line)
+}
+
+var appPrefix atomic.Pointer[string]
+
+// OnlyAppFrames limits rendered stacks (%+v, Render, Frames, StackLines and
+// LogFields) to frames of packages under modulePrefix, followed by a marker
+// counting the frames left out. An empty prefix stands for the main module
+// of the running binary. StackTrace still returns the full stack.
+func OnlyAppFrames(modulePrefix string) {
+	if modulePrefix == "" {
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			modulePrefix = bi.Main.Path
+		}
+	}
+	appPrefix.Store(&modulePrefix)
+}
+
+// AllFrames undoes OnlyAppFrames.
+func AllFrames() {
+	appPrefix.Store(nil)
+}
+
+var frameFilter atomic.Pointer[func(Frame) bool]
+
+// SetFrameFilter registers fn to decide which frames are rendered wherever
+// OnlyAppFrames applies; frames for which fn returns false are dropped
+// without a marker. A panicking filter keeps the frame. Stored stacks are
+// never modified. Pass nil to remove the filter.
+func SetFrameFilter(fn func(Frame) bool) {
+	if fn == nil {
+		frameFilter.Store(nil)
+		return
+	}
+	frameFilter.Store(&fn)
+}
+
+// filterStack returns the frames of st to render and how many of them were
+// dropped by OnlyAppFrames.
+func filterStack(st errors.StackTrace) (errors.StackTrace, int) {
+	prefix := appPrefix.Load()
+	if prefix != nil && *prefix == "" {
+		prefix = nil
+	}
+	filter := frameFilter.Load()
+	if prefix == nil && filter == nil {
+		return st, 0
+	}
+
+	kept := make(errors.StackTrace, 0, len(st))
+	external := 0
+	for _, f := range st {
+		function, file, line := frameInfo(f)
+		if prefix != nil && !inPackage(function, *prefix) {
+			external++
+			continue
+		}
+		if filter != nil && !keepFrame(*filter, Frame{Function: function, File: file, Line: line}) {
+			continue
+		}
+		kept = append(kept, f)
+	}
+	return kept, external
+}
+
+func keepFrame(filter func(Frame) bool, f Frame) (keep bool) {
+	defer func() {
+		if recover() != nil {
+			keep = true
+		}
+	}()
+	return filter(f)
+}
+
+func inPackage(function string, prefix string) bool {
+	if !strings.HasPrefix(function, prefix) {
+		return false
+	}
+	rest := function[len(prefix):]
+	return rest == "" || rest[0] == '/' || rest[0] == '.'
+}
+
+func externalFrames(n int) string {
+	return fmt.Sprintf("… %d external frames", n)
+}
+
+// Frames returns the resolved frames of the innermost stack in err's chain,
+// the one Caller, LogFields and Encode report, so that wrapping an error
+// does not change them.
+func Frames(err error) []Frame {
+	return resolveFrames(innermostStack(err))
+}
+
+// StackLines returns the innermost stack in err's chain, as Frames, as one
+// "function file:line" string per frame, keeping at most limit frames when
+// limit is positive. The slice is empty when the chain has no stack.
+func StackLines(err error, limit int) []string {
+	return stackLines(innermostStack(err), limit)
+}
+
+func resolveFrames(st errors.StackTrace) []Frame {
+	st, dropped := filterStack(st)
+	frames := make([]Frame, 0, len(st)+1)
+	for _, f := range st {
+		function, file, line := frameInfo(f)
+		frames = append(frames, Frame{Function: function, File: file, Line: line})
+	}
+	if dropped > 0 {
+		frames = append(frames, Frame{Function: externalFrames(dropped)})
+	}
+	return frames
+}
+
+func stackLines(st errors.StackTrace, limit int) []string {
+	frames := resolveFrames(st)
+	if limit > 0 && len(frames) > limit {
+		frames = frames[:limit]
+	}
+	lines := make([]string, 0, len(frames))
+	for _, f := range frames {
+		lines = append(lines, f.String())
+	}
+	return lines
+}
+
+// innermostStack returns the innermost stack of err's chain, skipping the
+// empty stacks of sampled and decoded errors.
+func innermostStack(err error) errors.StackTrace {
+	var stack errors.StackTrace
+	for err != nil {
+		if st, ok := err.(StackTracer); ok && len(st.StackTrace()) > 0 {
+			stack = st.StackTrace()
+		}
+		err = unwrapOnce(err)
+	}
+	return stack
+}
+
+// Caller returns the location where err was created: the first frame of the
+// innermost stack of its chain, so wrapping does not move it.
+func Caller(err error) (function string, file string, line int, ok bool) {
+	st := innermostStack(err)
+	if len(st) == 0 {
+		return "", "", 0, false
+	}
+	function, file, line = frameInfo(st[0])
+	return function, file, line, true
+}
+
+type frameLocation struct {
+	function string
+	file     string
+	line     int
+}
+
+// resolved caches resolved frames by pc; the set of pcs in a binary is finite.
+var resolved sync.Map
+
+func frameInfo(f errors.Frame) (function string, file string, line int) {
+	if v, ok := resolved.Load(f); ok {
+		loc := v.(frameLocation)
+		return loc.function, loc.file, loc.line
+	}
+
+	pc := uintptr(f) - 1
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return "unknown", "unknown", 0
+	}
+	file, line = fn.FileLine(pc)
+	resolved.Store(f, frameLocation{function: fn.Name(), file: file, line: line})
+	return fn.Name(), file, line
+}
+
+func trimPath(file string) string {
+	i := strings.LastIndexByte(file, '/')
+	if i < 0 {
+		return file
+	}
+	if j := strings.LastIndexByte(file[:i], '/'); j >= 0 {
+		return file[j+1:]
+	}
+	return file
+}
@@ -0,0 +1,152 @@
+package terrors
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestCaller(t *testing.T) {
+	err := Wrap(TypeInternal, fmt.Errorf("load: %w", formatInner()), "serve")
+
+	function, file, line, ok := Caller(err)
+	if !ok || function != "github.com/thamaji/terrors.formatInner" || !strings.HasSuffix(file, "/format_test.go") || line == 0 {
+		t.Errorf("Caller() = %s %s:%d %v", function, file, line, ok)
+	}
+
+	if _, _, _, ok := Caller(fmt.Errorf("plain")); ok {
+		t.Error("Caller() of an error without a stack reported ok")
+	}
+}
+
+func TestStackLines(t *testing.T) {
+	appFrames(t)
+
+	tests := []struct {
+		name  string
+		err   error
+		first string
+	}{
+		{"new", formatInner(), "github.com/thamaji/terrors.formatInner terrors/format_test.go:"},
+		{"wrap", formatOuter(), "github.com/thamaji/terrors.formatInner terrors/format_test.go:"},
+		{"foreign over wrap", fmt.Errorf("serve: %w", formatOuter()), "github.com/thamaji/terrors.formatInner terrors/format_test.go:"},
+		{"pkg/errors", errors.New("boom"), "github.com/thamaji/terrors.TestStackLines terrors/stack_test.go:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := StackLines(tt.err, 0)
+			if len(lines) < 2 {
+				t.Fatalf("StackLines() = %q", lines)
+			}
+			first := strings.ReplaceAll(lines[0], filepath.Base(mustGetwd(t))+"/", "terrors/")
+			if !strings.HasPrefix(first, tt.first) {
+				t.Errorf("first line = %q, want prefix %q", first, tt.first)
+			}
+			if last := lines[len(lines)-1]; last != "… 2 external frames" {
+				t.Errorf("last line = %q", last)
+			}
+
+			if limited := StackLines(tt.err, 1); len(limited) != 1 || limited[0] != lines[0] {
+				t.Errorf("StackLines(1) = %q", limited)
+			}
+		})
+	}
+}
+
+func TestStackLinesWithoutStack(t *testing.T) {
+	for _, err := range []error{nil, io.EOF, fmt.Errorf("wrapped: %w", io.EOF)} {
+		if lines := StackLines(err, 0); lines == nil || len(lines) != 0 {
+			t.Errorf("StackLines(%v) = %#v, want an empty slice", err, lines)
+		}
+	}
+}
+
+func mustGetwd(t *testing.T) string {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return wd
+}
+
+func TestOnlyAppFrames(t *testing.T) {
+	err := formatOuter()
+	all := Frames(err)
+
+	OnlyAppFrames("github.com/thamaji/terrors")
+	defer AllFrames()
+
+	frames := Frames(err)
+	if len(frames) != 5 {
+		t.Fatalf("Frames() = %v", frames)
+	}
+	for _, f := range frames[:4] {
+		if !strings.HasPrefix(f.Function, "github.com/thamaji/terrors.") {
+			t.Errorf("kept external frame %v", f)
+		}
+	}
+	if want := externalFrames(len(all) - 4); frames[4].Function != want || frames[4].File != "" {
+		t.Errorf("marker = %v, want %q", frames[4], want)
+	}
+
+	if st := innermostStack(err); len(st) != len(all) {
+		t.Errorf("innermost stack has %d frames, want %d", len(st), len(all))
+	}
+}
+
+func TestOnlyAppFramesPrefixBoundary(t *testing.T) {
+	OnlyAppFrames("github.com/thamaji/terr")
+	defer AllFrames()
+
+	frames := Frames(formatOuter())
+	if len(frames) != 1 {
+		t.Errorf("Frames() = %v, want only the marker", frames)
+	}
+}
+
+func TestSetFrameFilter(t *testing.T) {
+	err := formatOuter()
+	st := innermostStack(err)
+	before := fmt.Sprintf("%+v", st)
+
+	SetFrameFilter(func(f Frame) bool {
+		return !strings.HasPrefix(f.Function, "testing.")
+	})
+	defer SetFrameFilter(nil)
+
+	for _, f := range Frames(err) {
+		if strings.HasPrefix(f.Function, "testing.") {
+			t.Errorf("Frames() kept %v", f)
+		}
+	}
+	if lines := StackLines(err, 0); len(lines) != len(st)-1 {
+		t.Errorf("StackLines() = %q, want one frame less than %d", lines, len(st))
+	}
+	if strings.Contains(fmt.Sprintf("%+v", err), "testing.tRunner") {
+		t.Error("verbose format kept testing.tRunner")
+	}
+
+	after := innermostStack(err)
+	if got := fmt.Sprintf("%+v", after); got != before {
+		t.Errorf("StackTrace() changed:\n%s\nwant:\n%s", got, before)
+	}
+}
+
+func TestSetFrameFilterPanics(t *testing.T) {
+	SetFrameFilter(func(f Frame) bool { panic("filter bug") })
+	defer SetFrameFilter(nil)
+
+	err := formatOuter()
+	st := innermostStack(err)
+	if frames := Frames(err); len(frames) != len(st) {
+		t.Errorf("Frames() = %d frames, want all %d", len(frames), len(st))
+	}
+}
@@ -0,0 +1,44 @@
+package terrors
+
+import (
+	"strings"
+)
+
+// summaryMax bounds the length of Summary.
+const summaryMax = 64
+
+// Summary returns a short label for err fit for metrics, such as
+// "not_exist/userstore.Get": the type name and the outermost op, see Ops, or
+// the package err was created in when there is none, "unknown" when neither
+// is known. No message text is included, the result is at most 64 bytes of
+// [A-Za-z0-9_./-], and layers that add neither a type nor an op do not
+// change it. A nil error yields "".
+func Summary(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	where := "unknown"
+	if ops := Ops(err); len(ops) > 0 {
+		where = ops[0]
+	} else if function, _, _, ok := Caller(err); ok {
+		where = funcOp(function)
+		if i := strings.IndexByte(where, '.'); i >= 0 {
+			where = where[:i]
+		}
+	}
+
+	s := []byte(TypeOf(err).String() + "/" + where)
+	for i, c := range s {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case c == '_', c == '.', c == '/', c == '-':
+		default:
+			s[i] = '_'
+		}
+	}
+	if len(s) > summaryMax {
+		s = s[:summaryMax]
+	}
+	return string(s)
+}
@@ -0,0 +1,101 @@
+package terrors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"runtime/pprof"
+	"strings"
+	"testing"
+)
+
+func userstoreGet() error {
+	return WithOp(New(TypeNotExist, "user 42 not found"), "userstore.Get")
+}
+
+func TestSummaryStable(t *testing.T) {
+	base := userstoreGet()
+	const want = "not_exist/userstore.Get"
+
+	CaptureGoroutineLabels(true)
+	defer CaptureGoroutineLabels(false)
+	var labeled error
+	pprof.Do(context.Background(), pprof.Labels("tenant", "acme"), func(ctx context.Context) {
+		labeled = WithLabels(ctx, base)
+	})
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"base", base},
+		{"WithStack", WithStack(TypeNotExist, base)},
+		{"WithLabels", labeled},
+		{"WithDetails", WithDetails(base, map[string]int{"id": 42})},
+		{"WithSecondary", WithSecondary(base, New(TypeInternal, "rollback"))},
+		{"WithMessage", WithMessage(TypeNotExist, base, "lookup 42")},
+		{"fmt", fmt.Errorf("handler: %w", base)},
+		{"same op", WithOp(base, "userstore.Get")},
+		{"inner op", WithStack(TypeNotExist, WithOp(WithOp(New(TypeNotExist, "x"), "sql.Query"), "userstore.Get"))},
+		{"everything", fmt.Errorf("a: %w", WithStack(TypeNotExist, WithDetails(WithSecondary(labeled, stderrors.New("b")), 1)))},
+	}
+	for _, tt := range tests {
+		if got := Summary(tt.err); got != want {
+			t.Errorf("%s: Summary() = %q, want %q", tt.name, got, want)
+		}
+	}
+
+	// the outermost op and type are what Summary reports
+	if got := Summary(WithOp(base, "api.GetUser")); got != "not_exist/api.GetUser" {
+		t.Errorf("outer op: Summary() = %q", got)
+	}
+	if got := Summary(Wrap(TypeInternal, base, "load")); got != "internal/userstore.Get" {
+		t.Errorf("outer type: Summary() = %q", got)
+	}
+}
+
+func TestSummaryFallbacks(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"origin package", New(TypeTimeout, "slow"), "timeout/terrors"},
+		{"origin of a wrapped foreign error", Wrap(TypeTimeout, stderrors.New("slow"), "call"), "timeout/terrors"},
+		{"no op nor stack", stderrors.New("boom"), "unknown/unknown"},
+		{"untyped with op", WithOp(stderrors.New("boom"), "cache.Get"), "unknown/cache.Get"},
+		{"decoded", mustDecode(t, Wrap(TypeConflict, stderrors.New("busy"), "save")), "conflict/unknown"},
+		{"free-form op", WithOp(New(TypeInvalid, "x"), "user 42: get"), "invalid/user_42__get"},
+	}
+	for _, tt := range tests {
+		if got := Summary(tt.err); got != tt.want {
+			t.Errorf("%s: Summary() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if got := Summary(nil); got != "" {
+		t.Errorf("Summary(nil) = %q", got)
+	}
+}
+
+func mustDecode(t *testing.T, err error) error {
+	t.Helper()
+
+	decoded, derr := DecodeBinary(AppendBinary(nil, err))
+	if derr != nil {
+		t.Fatal(derr)
+	}
+	return decoded
+}
+
+func TestSummaryLength(t *testing.T) {
+	op := "example.com/" + strings.Repeat("very_long_package_name/", 5) + "store.(*DB).Get"
+	got := Summary(WithOp(New(TypeUnavailable, "down"), op))
+	if len(got) != summaryMax || !strings.HasPrefix(got, "unavailable/example.com/very_long_package_name/") {
+		t.Errorf("Summary() = %q (%d bytes), want the first %d bytes", got, len(got), summaryMax)
+	}
+	for _, c := range got {
+		if !strings.ContainsRune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_./-", c) {
+			t.Errorf("Summary() = %q holds %q", got, c)
+		}
+	}
+}
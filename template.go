@@ -0,0 +1,58 @@
+package terrors
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	templatesMu sync.RWMutex
+	templates   = map[Type]*template.Template{}
+)
+
+// SetTemplate registers the text/template used by NewT for errors of type t.
+func SetTemplate(t Type, tmpl string) error {
+	parsed, err := template.New(t.String()).Parse(tmpl)
+	if err != nil {
+		return Wrap(TypeInvalid, err, "terrors: parse template")
+	}
+
+	templatesMu.Lock()
+	templates[t] = parsed
+	templatesMu.Unlock()
+	return nil
+}
+
+// NewT creates an error of type t whose message is the template registered
+// for t executed with data, or fmt.Sprint(data) when there is none. data is
+// attached to the error and can be read back with Details. A template that
+// fails to execute yields a TypeInternal error instead.
+func NewT(t Type, data interface{}) error {
+	stack := errors.New("").(StackTracer).StackTrace()[1:]
+
+	msg, err := executeTemplate(t, data)
+	if err != nil {
+		return newWrapped(TypeInternal, err, "terrors: execute template for "+t.String(), stack)
+	}
+
+	return &withDetails{cause: newFundamental(t, msg, stack), details: data}
+}
+
+func executeTemplate(t Type, data interface{}) (string, error) {
+	templatesMu.RLock()
+	tmpl, ok := templates[t]
+	templatesMu.RUnlock()
+	if !ok {
+		return fmt.Sprint(data), nil
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,111 @@
+package terrors
+
+import (
+	"strings"
+	"testing"
+)
+
+type quotaData struct {
+	User  string
+	Limit int
+}
+
+type panicky struct{}
+
+func (panicky) Name() string {
+	panic("name not loaded")
+}
+
+// setTemplate registers tmpl for t until the end of the test.
+func setTemplate(t *testing.T, typ Type, tmpl string) {
+	t.Helper()
+
+	if err := SetTemplate(typ, tmpl); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		templatesMu.Lock()
+		delete(templates, typ)
+		templatesMu.Unlock()
+	})
+}
+
+func TestNewT(t *testing.T) {
+	setTemplate(t, TypeUnavailable, "{{.User}} is over the limit of {{.Limit}} requests")
+
+	data := quotaData{User: "alice", Limit: 100}
+	err := NewT(TypeUnavailable, data)
+	if err.Error() != "alice is over the limit of 100 requests" || TypeOf(err) != TypeUnavailable {
+		t.Errorf("NewT() = %q (%v)", err, TypeOf(err))
+	}
+	if d, ok := Details[quotaData](err); !ok || d != data {
+		t.Errorf("Details() = %v, %v; want %v", d, ok, data)
+	}
+	if function, _, _, ok := Caller(err); !ok || !strings.HasSuffix(function, ".TestNewT") {
+		t.Errorf("Caller() = %s", function)
+	}
+
+	// the details survive wrapping
+	if d, ok := Details[quotaData](Wrap(TypeInternal, err, "call api")); !ok || d != data {
+		t.Errorf("Details() of a wrapped error = %v, %v", d, ok)
+	}
+}
+
+func TestNewTWithoutTemplate(t *testing.T) {
+	err := NewT(TypeTimeout, quotaData{User: "bob", Limit: 3})
+	if err.Error() != "{bob 3}" || TypeOf(err) != TypeTimeout {
+		t.Errorf("NewT() = %q (%v), want the fmt.Sprint fallback", err, TypeOf(err))
+	}
+	if got := NewT(TypeTimeout, "slow upstream").Error(); got != "slow upstream" {
+		t.Errorf("NewT() of a string = %q", got)
+	}
+	if got := NewT(TypeTimeout, nil).Error(); got != "<nil>" {
+		t.Errorf("NewT() of nil = %q", got)
+	}
+}
+
+func TestNewTExecuteError(t *testing.T) {
+	tests := []struct {
+		name string
+		tmpl string
+		data interface{}
+	}{
+		{"missing field", "{{.Missing}}", quotaData{}},
+		{"index out of range", "{{index . 5}}", []string{"a"}},
+		{"panicking method", "{{.Name}}", panicky{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTemplate(t, TypeConflict, tt.tmpl)
+
+			var err error
+			func() {
+				defer func() {
+					if p := recover(); p != nil {
+						t.Fatalf("NewT() panicked: %v", p)
+					}
+				}()
+				err = NewT(TypeConflict, tt.data)
+			}()
+
+			if TypeOf(err) != TypeInternal || !strings.HasPrefix(err.Error(), "terrors: execute template for conflict: ") {
+				t.Errorf("NewT() = %q (%v), want a TypeInternal error", err, TypeOf(err))
+			}
+			if _, ok := Details[quotaData](err); ok {
+				t.Error("a failed NewT() has details")
+			}
+		})
+	}
+}
+
+func TestSetTemplateInvalid(t *testing.T) {
+	if err := SetTemplate(TypeConflict, "{{.User"); TypeOf(err) != TypeInvalid {
+		t.Errorf("SetTemplate() = %v, want a TypeInvalid error", err)
+	}
+	templatesMu.RLock()
+	_, ok := templates[TypeConflict]
+	templatesMu.RUnlock()
+	if ok {
+		t.Error("an invalid template was registered")
+	}
+}
@@ -3,6 +3,9 @@ package terrors
 import (
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
+	"sync/atomic"
 
 	"github.com/pkg/errors"
 )
@@ -18,27 +21,98 @@ const (
 	TypeInternal
 	TypeUnauthorized
 	TypeNotError
+	TypeConflict
+	TypeCanceled
+	TypeTimeout
+	TypeUnavailable
 )
 
-type stackTracer interface {
+func (t Type) String() string {
+	switch t {
+	case TypeUnknown:
+		return "unknown"
+	case TypeInvalid:
+		return "invalid"
+	case TypePermission:
+		return "permission"
+	case TypeExist:
+		return "exist"
+	case TypeNotExist:
+		return "not_exist"
+	case TypeInternal:
+		return "internal"
+	case TypeUnauthorized:
+		return "unauthorized"
+	case TypeNotError:
+		return "not_error"
+	case TypeConflict:
+		return "conflict"
+	case TypeCanceled:
+		return "canceled"
+	case TypeTimeout:
+		return "timeout"
+	case TypeUnavailable:
+		return "unavailable"
+	}
+	if name, ok := registeredName(t); ok {
+		return name
+	}
+	return fmt.Sprintf("Type(%d)", int(t))
+}
+
+// ParseType returns the type whose String() is name, including the types
+// defined with RegisterType and the "Type(N)" names of the other values, so
+// that every Type survives a round trip through its name.
+func ParseType(name string) (Type, bool) {
+	for t := TypeUnknown; t <= TypeUnavailable; t++ {
+		if t.String() == name {
+			return t, true
+		}
+	}
+	if t, ok := registeredType(name); ok {
+		return t, true
+	}
+
+	if n, ok := strings.CutPrefix(name, "Type("); ok {
+		if i, err := strconv.Atoi(strings.TrimSuffix(n, ")")); err == nil && Type(i).String() == name {
+			return Type(i), true
+		}
+	}
+	return TypeUnknown, false
+}
+
+type TypedError interface {
+	error
+	Type() Type
+}
+
+type StackTracer interface {
 	StackTrace() errors.StackTrace
 }
 
 func New(t Type, msg string) error {
-	stack := errors.New(msg).(stackTracer).StackTrace()
-	return &fundamental{t: t, msg: msg, stack: stack[1:]}
+	stack := errors.New(msg).(StackTracer).StackTrace()
+	return newFundamental(t, msg, stack[1:])
 }
 
 func Errorf(t Type, format string, args ...interface{}) error {
 	msg := fmt.Sprintf(format, args...)
-	stack := errors.New(msg).(stackTracer).StackTrace()
-	return &fundamental{t: t, msg: msg, stack: stack[1:]}
+	stack := errors.New(msg).(StackTracer).StackTrace()
+	return newFundamental(t, msg, stack[1:])
 }
 
 type fundamental struct {
-	t     Type
-	msg   string
-	stack errors.StackTrace
+	t      Type
+	msg    string
+	stack  errors.StackTrace
+	labels map[string]string
+	id     uint64
+}
+
+// newFundamental returns a new error, with the labels of the goroutine and a
+// new id, as do newWithStack and newWrapped.
+func newFundamental(t Type, msg string, stack errors.StackTrace) *fundamental {
+	return &fundamental{t: t, msg: msg, stack: stack, labels: goroutineLabels(), id: newID()}
 }
 
 func (f *fundamental) Type() Type {
@@ -53,12 +127,23 @@ func (f *fundamental) StackTrace() errors.StackTrace {
 	return f.stack
 }
 
+func (f *fundamental) labelSet() map[string]string {
+	return f.labels
+}
+
+func (f *fundamental) instanceID() uint64 {
+	return f.id
+}
+
+func (f *fundamental) Is(target error) bool {
+	return sentinelIs(f.t, target)
+}
+
 func (f *fundamental) Format(s fmt.State, verb rune) {
 	switch verb {
 	case 'v':
 		if s.Flag('+') {
-			io.WriteString(s, f.msg)
-			f.stack.Format(s, verb)
+			formatVerbose(s, f)
 			return
 		}
 		fallthrough
@@ -73,14 +158,20 @@ func WithStack(t Type, err error) error {
 	if err == nil {
 		return nil
 	}
-	stack := errors.New("").(stackTracer).StackTrace()
-	return &withStack{t: t, cause: err, stack: stack[1:]}
+	stack := errors.New("").(StackTracer).StackTrace()
+	return newWithStack(t, err, stack[1:])
 }
 
 type withStack struct {
-	t     Type
-	cause error
-	stack errors.StackTrace
+	t      Type
+	cause  error
+	stack  errors.StackTrace
+	labels map[string]string
+	id     uint64
+}
+
+func newWithStack(t Type, cause error, stack errors.StackTrace) *withStack {
+	return &withStack{t: t, cause: cause, stack: stack, labels: goroutineLabels(), id: newID()}
 }
 
 func (w *withStack) Type() Type {
@@ -95,16 +186,31 @@ func (w *withStack) Cause() error {
 	return w.cause
 }
 
+func (w *withStack) Unwrap() error {
+	return w.cause
+}
+
 func (w *withStack) StackTrace() errors.StackTrace {
 	return w.stack
 }
 
+func (w *withStack) labelSet() map[string]string {
+	return w.labels
+}
+
+func (w *withStack) instanceID() uint64 {
+	return w.id
+}
+
+func (w *withStack) Is(target error) bool {
+	return sentinelIs(w.t, target)
+}
+
 func (w *withStack) Format(s fmt.State, verb rune) {
 	switch verb {
 	case 'v':
 		if s.Flag('+') {
-			fmt.Fprintf(s, "%+v", w.Cause())
-			w.StackTrace().Format(s, verb)
+			formatVerbose(s, w)
 			return
 		}
 		fallthrough
@@ -119,22 +225,138 @@ func Wrap(t Type, err error, msg string) error {
 	if err == nil {
 		return nil
 	}
-	stack := errors.New("").(stackTracer).StackTrace()
-	return &withStack{t: t, cause: &withMessage{t: t, cause: err, msg: msg}, stack: stack[1:]}
+	stack := errors.New("").(StackTracer).StackTrace()
+	if duplicateMessage(err, msg) {
+		return newWithStack(t, err, stack[1:])
+	}
+	return newWrapped(t, err, msg, stack[1:])
 }
 
 func Wrapf(t Type, err error, format string, args ...interface{}) error {
 	if err == nil {
 		return nil
 	}
-	stack := errors.New("").(stackTracer).StackTrace()
-	return &withStack{t: t, cause: &withMessage{t: t, cause: err, msg: fmt.Sprintf(format, args...)}, stack: stack[1:]}
+	msg := fmt.Sprintf(format, args...)
+	stack := errors.New("").(StackTracer).StackTrace()
+	if duplicateMessage(err, msg) {
+		return newWithStack(t, err, stack[1:])
+	}
+	return newWrapped(t, err, msg, stack[1:])
+}
+
+// WrapOrNew is like Wrap when err is non-nil and like New otherwise: unlike
+// Wrap it never returns nil.
+func WrapOrNew(t Type, err error, msg string) error {
+	stack := errors.New("").(StackTracer).StackTrace()
+	if err == nil {
+		return newFundamental(t, msg, stack[1:])
+	}
+	return newWrapped(t, err, msg, stack[1:])
+}
+
+func WrapOrNewf(t Type, err error, format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	stack := errors.New("").(StackTracer).StackTrace()
+	if err == nil {
+		return newFundamental(t, msg, stack[1:])
+	}
+	return newWrapped(t, err, msg, stack[1:])
+}
+
+// WrapAll wraps every non-nil error of errs like Wrapf, with the arguments
+// for the i-th error returned by argsFn(i). All the wrappers share a single
+// stack. The result has the same length as errs, with nil entries kept.
+func WrapAll(t Type, errs []error, format string, argsFn func(i int) []interface{}) []error {
+	if errs == nil {
+		return nil
+	}
+
+	var stack errors.StackTrace
+	result := make([]error, len(errs))
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		if stack == nil {
+			stack = errors.New("").(StackTracer).StackTrace()[1:]
+		}
+		var args []interface{}
+		if argsFn != nil {
+			args = argsFn(i)
+		}
+		result[i] = newWrapped(t, err, fmt.Sprintf(format, args...), stack)
+	}
+	return result
+}
+
+type wrapped struct {
+	t      Type
+	cause  error
+	msg    string
+	stack  errors.StackTrace
+	labels map[string]string
+	id     uint64
+	text   atomic.Pointer[string]
+}
+
+func newWrapped(t Type, cause error, msg string, stack errors.StackTrace) *wrapped {
+	return &wrapped{t: t, cause: cause, msg: msg, stack: stack, labels: goroutineLabels(), id: newID()}
+}
+
+func (w *wrapped) Type() Type {
+	return w.t
+}
+
+func (w *wrapped) Error() string {
+	return cachedMessage(&w.text, w)
+}
+
+func (w *wrapped) Cause() error {
+	return w.cause
+}
+
+func (w *wrapped) Unwrap() error {
+	return w.cause
+}
+
+func (w *wrapped) StackTrace() errors.StackTrace {
+	return w.stack
+}
+
+func (w *wrapped) labelSet() map[string]string {
+	return w.labels
+}
+
+func (w *wrapped) instanceID() uint64 {
+	return w.id
+}
+
+func (w *wrapped) Is(target error) bool {
+	return sentinelIs(w.t, target)
+}
+
+func (w *wrapped) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			formatVerbose(s, w)
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, w.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", w.Error())
+	}
 }
 
 func WithMessage(t Type, err error, message string) error {
 	if err == nil {
 		return nil
 	}
+	if duplicateMessage(err, message) {
+		return &withStack{t: t, cause: err}
+	}
 	return &withMessage{t: t, cause: err, msg: message}
 }
 
@@ -142,6 +364,7 @@ type withMessage struct {
 	t     Type
 	cause error
 	msg   string
+	text  atomic.Pointer[string]
 }
 
 func (w *withMessage) Type() Type {
@@ -149,19 +372,26 @@ func (w *withMessage) Type() Type {
 }
 
 func (w *withMessage) Error() string {
-	return w.msg + ": " + w.cause.Error()
+	return cachedMessage(&w.text, w)
 }
 
 func (w *withMessage) Cause() error {
 	return w.cause
 }
 
+func (w *withMessage) Unwrap() error {
+	return w.cause
+}
+
+func (w *withMessage) Is(target error) bool {
+	return sentinelIs(w.t, target)
+}
+
 func (w *withMessage) Format(s fmt.State, verb rune) {
 	switch verb {
 	case 'v':
 		if s.Flag('+') {
-			fmt.Fprintf(s, "%+v\n", w.Cause())
-			io.WriteString(s, w.msg)
+			formatVerbose(s, w)
 			return
 		}
 		fallthrough
@@ -170,34 +400,237 @@ func (w *withMessage) Format(s fmt.State, verb rune) {
 	}
 }
 
+// joinMessages renders the message of a chain in a single pass over the
+// layers owned by this package, so that a deep chain is not concatenated once
+// per layer.
+func joinMessages(err error) string {
+	n := 0
+	leaf := err
+	for {
+		msg, prefix, cause, ok := layerMessage(leaf)
+		if !ok {
+			break
+		}
+		if prefix {
+			n += len(msg) + len(": ")
+		}
+		leaf = cause
+	}
+
+	tail := leaf.Error()
+
+	var b strings.Builder
+	b.Grow(n + len(tail))
+	for e := err; e != leaf; {
+		msg, prefix, cause, _ := layerMessage(e)
+		if prefix {
+			b.WriteString(msg)
+			b.WriteString(": ")
+		}
+		e = cause
+	}
+	b.WriteString(tail)
+	return b.String()
+}
+
+// cachedMessage returns the message of err, joined once and kept in text:
+// chains are immutable, so the message of a wrapper never changes unless a
+// foreign error below it changes its own.
+func cachedMessage(text *atomic.Pointer[string], err error) string {
+	if s := text.Load(); s != nil {
+		return *s
+	}
+	s := joinMessages(err)
+	text.Store(&s)
+	return s
+}
+
+// layerMessage returns the message err puts in front of the message of its
+// cause. prefix is set for the layers that put "msg: " there, even when msg
+// is empty.
+func layerMessage(err error) (msg string, prefix bool, cause error, ok bool) {
+	switch e := err.(type) {
+	case *withMessage:
+		return e.msg, true, e.cause, true
+	case *wrapped:
+		return e.msg, true, e.cause, true
+	case *withStack:
+		return "", false, e.cause, true
+	case *withLabels:
+		return "", false, e.cause, true
+	case *withDetails:
+		return "", false, e.cause, true
+	case *withHandled:
+		return "", false, e.cause, true
+	case *withSecondary:
+		return "", false, e.cause, true
+	case *withOp:
+		return "", false, e.cause, true
+	case *withPlainMessage:
+		return e.msg, true, e.cause, true
+	}
+	return "", false, nil, false
+}
+
 func Cause(err error) error {
+	for err != nil {
+		cause := unwrapOnce(err)
+		if cause == nil {
+			break
+		}
+		err = cause
+	}
+	return err
+}
+
+// Unwrap returns the error that err wraps, following Cause() before
+// Unwrap() error as every function of this package does, or nil. It does
+// not descend into joins.
+func Unwrap(err error) error {
+	return unwrapOnce(err)
+}
+
+func unwrapOnce(err error) error {
 	type causer interface {
 		Cause() error
 	}
 
+	type wrapper interface {
+		Unwrap() error
+	}
+
+	switch e := err.(type) {
+	case causer:
+		return e.Cause()
+	case wrapper:
+		return e.Unwrap()
+	}
+	return nil
+}
+
+// walk calls fn for err and every error it wraps, depth first, until fn
+// returns false.
+func walk(err error, fn func(error) bool) bool {
 	for err != nil {
-		cause, ok := err.(causer)
-		if !ok {
-			break
+		if !fn(err) {
+			return false
+		}
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range joined.Unwrap() {
+				if !walk(e, fn) {
+					return false
+				}
+			}
+			return true
 		}
-		err = cause.Cause()
+		err = unwrapOnce(err)
 	}
-	return err
+	return true
 }
 
+// TypeOf is the same as OuterType: a wrapper's type takes precedence over the
+// types of the errors it wraps.
 func TypeOf(err error) Type {
+	return OuterType(err)
+}
+
+// OuterType returns the type of the first typed layer found walking err's
+// chain from the outside in.
+func OuterType(err error) Type {
 	if err == nil {
 		return TypeNotError
 	}
 
-	type typer interface {
-		Type() Type
+	t, ok := TypeOk(err)
+	if !ok {
+		return untypedType(err)
 	}
+	return t
+}
 
-	e, ok := err.(typer)
-	if !ok {
-		return TypeUnknown
+// TypeOk is like OuterType but also reports whether any layer of err's chain
+// is typed at all. It returns (TypeUnknown, false) for nil and for chains
+// without a typed layer. When the chain reaches a join (Unwrap() []error)
+// before a typed layer, the type of highest priority among the joined errors
+// wins, see SetTypePriority.
+func TypeOk(err error) (Type, bool) {
+	for ; err != nil; err = unwrapOnce(err) {
+		if e, ok := err.(TypedError); ok {
+			return e.Type(), true
+		}
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			return joinedType(joined.Unwrap())
+		}
 	}
 
-	return e.Type()
+	return TypeUnknown, false
+}
+
+// RootType returns the type of the deepest typed layer of err's chain.
+func RootType(err error) Type {
+	if err == nil {
+		return TypeNotError
+	}
+
+	t, found := TypeUnknown, false
+	for e := err; e != nil; e = unwrapOnce(e) {
+		if te, ok := e.(TypedError); ok {
+			t, found = te.Type(), true
+		}
+	}
+	if !found {
+		return untypedType(err)
+	}
+	return t
+}
+
+var defaultType atomic.Int64
+
+// SetDefaultType sets the type reported by TypeOf, OuterType and RootType for
+// errors without any typed layer, unless RegisterPackageDefault applies. It
+// defaults to TypeUnknown and is meant to be set once at startup.
+func SetDefaultType(t Type) {
+	defaultType.Store(int64(t))
+}
+
+func DefaultType() Type {
+	return Type(defaultType.Load())
+}
+
+func HasType(err error, t Type) bool {
+	_, ok := FindType(err, t)
+	return ok
+}
+
+func AsTyped(err error) (TypedError, bool) {
+	return FindTyped(err)
+}
+
+// FindType returns the outermost error of err's chain whose Type() is t.
+// Chains are searched depth first: a layer before the errors it wraps, and
+// the errors of a join (Unwrap() []error) in order.
+func FindType(err error, t Type) (error, bool) {
+	var found error
+	walk(err, func(e error) bool {
+		if te, ok := e.(TypedError); ok && te.Type() == t {
+			found = e
+			return false
+		}
+		return true
+	})
+	return found, found != nil
+}
+
+// FindTyped returns the outermost typed error of err's chain, searched in
+// the same order as FindType.
+func FindTyped(err error) (TypedError, bool) {
+	var found TypedError
+	walk(err, func(e error) bool {
+		if te, ok := e.(TypedError); ok {
+			found = te
+			return false
+		}
+		return true
+	})
+	return found, found != nil
 }
@@ -0,0 +1,433 @@
+package terrors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestCause(t *testing.T) {
+	root := io.ErrUnexpectedEOF
+	typedRoot := New(TypeInvalid, "bad")
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"terrors", Wrap(TypeInternal, WithStack(TypeInvalid, root), "read"), root},
+		{"pkg/errors", errors.Wrap(errors.WithStack(root), "read"), root},
+		{"fmt", fmt.Errorf("read: %w", fmt.Errorf("body: %w", root)), root},
+		{"terrors over fmt", Wrap(TypeInternal, fmt.Errorf("body: %w", root), "read"), root},
+		{"fmt over terrors", fmt.Errorf("read: %w", WithMessage(TypeInvalid, root, "body")), root},
+		{"pkg/errors over fmt over terrors", errors.Wrap(fmt.Errorf("body: %w", typedRoot), "read"), typedRoot},
+		{"fmt over pkg/errors over terrors", fmt.Errorf("read: %w", errors.WithMessage(WithStack(TypeInvalid, root), "body")), root},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Cause(tt.err); got != tt.want {
+				t.Errorf("Cause() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCauseMixedTypes(t *testing.T) {
+	err := fmt.Errorf("handler: %w", errors.Wrap(Wrap(TypeNotExist, root(), "load"), "serve"))
+
+	if got := Cause(err); got != io.EOF {
+		t.Errorf("Cause() = %v, want io.EOF", got)
+	}
+	if got := TypeOf(err); got != TypeNotExist {
+		t.Errorf("TypeOf() = %v, want not_exist", got)
+	}
+	if !stderrors.Is(err, io.EOF) {
+		t.Error("errors.Is(err, io.EOF) = false")
+	}
+	if want := "handler: serve: load: EOF"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestCauseNil(t *testing.T) {
+	if got := Cause(nil); got != nil {
+		t.Errorf("Cause(nil) = %v", got)
+	}
+}
+
+func root() error {
+	return io.EOF
+}
+
+func TestOuterRootType(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		outer Type
+		root  Type
+	}{
+		{"nil", nil, TypeNotError, TypeNotError},
+		{"untyped", io.EOF, TypeUnknown, TypeUnknown},
+		{"single", New(TypeInvalid, "bad"), TypeInvalid, TypeInvalid},
+		{"differ", Wrap(TypeInternal, New(TypeNotExist, "missing"), "load"), TypeInternal, TypeNotExist},
+		{"three layers", WithMessage(TypePermission, Wrap(TypeInternal, New(TypeNotExist, "missing"), "load"), "serve"), TypePermission, TypeNotExist},
+		{"only outer typed", Wrap(TypeTimeout, fmt.Errorf("dial: %w", io.EOF), "connect"), TypeTimeout, TypeTimeout},
+		{"only inner typed", fmt.Errorf("serve: %w", errors.Wrap(New(TypeConflict, "busy"), "lock")), TypeConflict, TypeConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OuterType(tt.err); got != tt.outer {
+				t.Errorf("OuterType() = %v, want %v", got, tt.outer)
+			}
+			if got := TypeOf(tt.err); got != tt.outer {
+				t.Errorf("TypeOf() = %v, want %v", got, tt.outer)
+			}
+			if got := RootType(tt.err); got != tt.root {
+				t.Errorf("RootType() = %v, want %v", got, tt.root)
+			}
+		})
+	}
+}
+
+// chain wraps a root error depth times with alternating Wrap and
+// WithMessage layers.
+func chain(depth int) error {
+	err := New(TypeNotExist, "missing")
+	for i := 0; i < depth; i++ {
+		if i%2 == 0 {
+			err = Wrap(TypeInternal, err, "layer")
+		} else {
+			err = WithMessage(TypeInternal, err, "layer")
+		}
+	}
+	return err
+}
+
+func BenchmarkWrap(b *testing.B) {
+	err := io.EOF
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = Wrap(TypeInternal, err, "read")
+	}
+}
+
+func BenchmarkTypeOf(b *testing.B) {
+	err := fmt.Errorf("outer: %w", chain(10))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = TypeOf(err)
+	}
+}
+
+func BenchmarkRootType(b *testing.B) {
+	err := chain(10)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = RootType(err)
+	}
+}
+
+func TestErrorEmptyMessage(t *testing.T) {
+	// the baseline output: an empty message still adds its separator
+	inner := New(TypeNotExist, "inner")
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"WithMessage", WithMessage(TypeInvalid, inner, ""), ": inner"},
+		{"Wrap", Wrap(TypeInvalid, inner, ""), ": inner"},
+		{"Wrapf", Wrapf(TypeInvalid, inner, ""), ": inner"},
+		{"stacked", Wrap(TypeInvalid, WithMessage(TypeInvalid, inner, ""), "outer"), "outer: : inner"},
+		{"under WithStack", WithStack(TypeInvalid, Wrap(TypeInvalid, inner, "")), ": inner"},
+		{"foreign cause", Wrap(TypeInvalid, io.EOF, ""), ": EOF"},
+	}
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("%s: Error() = %q, want %q", tt.name, got, tt.want)
+		}
+		if got := fmt.Sprintf("%s", tt.err); got != tt.want {
+			t.Errorf("%s: %%s = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestErrorConcurrent(t *testing.T) {
+	build := func() error {
+		err := error(New(TypeNotExist, "missing"))
+		for i := 0; i < 20; i++ {
+			if i%5 == 4 {
+				err = fmt.Errorf("foreign %d: %w", i, err)
+				continue
+			}
+			err = Wrapf(TypeInternal, err, "layer %d", i)
+		}
+		return err
+	}
+	want := joinMessages(build())
+
+	// every goroutine races to fill the caches of the same fresh chain
+	err := build()
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			for j := 0; j < 100; j++ {
+				if got := err.Error(); got != want {
+					t.Errorf("Error() = %q, want %q", got, want)
+					return
+				}
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+}
+
+func BenchmarkErrorDeep(b *testing.B) {
+	err := chain(20)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = joinMessages(err)
+	}
+}
+
+// BenchmarkErrorThrice measures a request logging, counting and answering
+// a 10-deep error: three messages of a fresh chain, joined each time or
+// cached by Error.
+func BenchmarkErrorThrice(b *testing.B) {
+	b.Run("joined", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			err := chain(10)
+			for j := 0; j < 3; j++ {
+				_ = joinMessages(err)
+			}
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			err := chain(10)
+			for j := 0; j < 3; j++ {
+				_ = err.Error()
+			}
+		}
+	})
+}
+
+func TestWrapAll(t *testing.T) {
+	errs := []error{io.EOF, nil, New(TypeNotExist, "missing"), nil}
+	got := WrapAll(TypeInternal, errs, "item %d", func(i int) []interface{} { return []interface{}{i} })
+
+	if len(got) != len(errs) {
+		t.Fatalf("len = %d, want %d", len(got), len(errs))
+	}
+	if got[1] != nil || got[3] != nil {
+		t.Errorf("nil entries were wrapped: %v", got)
+	}
+	if got[0].Error() != "item 0: EOF" || got[2].Error() != "item 2: missing" {
+		t.Errorf("messages = %q, %q", got[0], got[2])
+	}
+
+	st0, _ := StackTrace(got[0])
+	st2, _ := StackTrace(got[2])
+	if &st0[0] != &st2[0] {
+		t.Error("wrappers do not share their stack")
+	}
+	if fn := fmt.Sprintf("%n", st0[0]); fn != "TestWrapAll" {
+		t.Errorf("stack starts at %s, want TestWrapAll", fn)
+	}
+	if ID(got[0]) == ID(got[2]) {
+		t.Error("wrappers share their id")
+	}
+}
+
+func TestWrapAllEdges(t *testing.T) {
+	if got := WrapAll(TypeInternal, nil, "x", nil); got != nil {
+		t.Errorf("WrapAll(nil) = %v", got)
+	}
+	if got := WrapAll(TypeInternal, []error{nil, nil}, "x", nil); len(got) != 2 || got[0] != nil || got[1] != nil {
+		t.Errorf("WrapAll(nils) = %v", got)
+	}
+	if got := WrapAll(TypeInternal, []error{io.EOF}, "no args", nil); got[0].Error() != "no args: EOF" {
+		t.Errorf("WrapAll without argsFn = %v", got)
+	}
+}
+
+func TestSetDefaultType(t *testing.T) {
+	SetDefaultType(TypeInternal)
+	defer SetDefaultType(TypeUnknown)
+
+	tests := []struct {
+		name string
+		err  error
+		want Type
+	}{
+		{"nil", nil, TypeNotError},
+		{"untyped", io.EOF, TypeInternal},
+		{"untyped chain", fmt.Errorf("a: %w", errors.Wrap(io.EOF, "b")), TypeInternal},
+		{"typed root", fmt.Errorf("a: %w", New(TypeInvalid, "b")), TypeInvalid},
+		{"typed outer", WithStack(TypeConflict, io.EOF), TypeConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TypeOf(tt.err); got != tt.want {
+				t.Errorf("TypeOf() = %v, want %v", got, tt.want)
+			}
+			if got := RootType(tt.err); got != tt.want {
+				t.Errorf("RootType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got, ok := TypeOk(io.EOF); ok || got != TypeUnknown {
+		t.Errorf("TypeOk() = %v, %v; the default must not count as a type", got, ok)
+	}
+	if DefaultType() != TypeInternal {
+		t.Errorf("DefaultType() = %v", DefaultType())
+	}
+}
+
+func TestWrapOrNew(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		new  func(err error) error
+		msg  string
+		root Type
+	}{
+		{"nil", nil, func(err error) error { return WrapOrNew(TypeNotExist, err, "missing") }, "missing", TypeNotExist},
+		{"non-nil", io.EOF, func(err error) error { return WrapOrNew(TypeNotExist, err, "missing") }, "missing: EOF", TypeNotExist},
+		{"nil f", nil, func(err error) error { return WrapOrNewf(TypeInvalid, err, "field %s", "name") }, "field name", TypeInvalid},
+		{"non-nil f", New(TypeTimeout, "slow"), func(err error) error { return WrapOrNewf(TypeInvalid, err, "field %s", "name") }, "field name: slow", TypeTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.new(tt.err)
+			if err == nil || err.Error() != tt.msg {
+				t.Fatalf("got %v, want %q", err, tt.msg)
+			}
+			if tt.err != nil && Cause(err) != Cause(tt.err) {
+				t.Errorf("Cause() = %v, want %v", Cause(err), tt.err)
+			}
+			if RootType(err) != tt.root {
+				t.Errorf("RootType() = %v, want %v", RootType(err), tt.root)
+			}
+			if st, _ := StackTrace(err); !strings.HasPrefix(fmt.Sprintf("%n", st[0]), "TestWrapOrNew.func") {
+				t.Errorf("stack starts at %n, want the caller", st[0])
+			}
+		})
+	}
+}
+
+func TestAsTyped(t *testing.T) {
+	notExist := New(TypeNotExist, "missing")
+	timeout := New(TypeTimeout, "slow")
+	wrapped := Wrap(TypeInternal, notExist, "load")
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"nil", nil, nil},
+		{"untyped", io.EOF, nil},
+		{"untyped chain", fmt.Errorf("read: %w", io.EOF), nil},
+		{"typed", notExist, notExist},
+		{"outermost typed layer", wrapped, wrapped},
+		{"under fmt", fmt.Errorf("handler: %w", notExist), notExist},
+		{"in a join", stderrors.Join(io.EOF, timeout), timeout},
+		{"under fmt in a join", stderrors.Join(io.EOF, fmt.Errorf("call: %w", timeout), notExist), timeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			te, ok := AsTyped(tt.err)
+			if ok != (tt.want != nil) {
+				t.Fatalf("AsTyped() = %v, %v", te, ok)
+			}
+			if tt.want == nil {
+				if te != nil {
+					t.Errorf("AsTyped() = %v, want nil", te)
+				}
+				return
+			}
+			if te.(error) != tt.want || te.Type() != tt.want.(TypedError).Type() {
+				t.Errorf("AsTyped() = %q (%v), want %q", te, te.Type(), tt.want)
+			}
+		})
+	}
+}
+
+func TestFindType(t *testing.T) {
+	a := New(TypeNotExist, "a")
+	b := New(TypeNotExist, "b")
+	outer := Wrap(TypeNotExist, New(TypeNotExist, "inner"), "outer")
+
+	tests := []struct {
+		name string
+		err  error
+		t    Type
+		want error
+	}{
+		{"nil", nil, TypeNotExist, nil},
+		{"no match", Wrap(TypeInternal, io.EOF, "read"), TypeNotExist, nil},
+		{"no match in a join", stderrors.Join(io.EOF, New(TypeInvalid, "x")), TypeNotExist, nil},
+		{"outermost first", outer, TypeNotExist, outer},
+		{"layer before its cause", Wrap(TypeInternal, outer, "load"), TypeNotExist, outer},
+		{"join in order", stderrors.Join(a, b), TypeNotExist, a},
+		{"join depth first", stderrors.Join(Wrap(TypeInvalid, a, "x"), b), TypeNotExist, a},
+		{"join skipping a child", stderrors.Join(io.EOF, New(TypeInvalid, "x"), b), TypeNotExist, b},
+		{"nested join", Wrap(TypeInternal, stderrors.Join(stderrors.Join(io.EOF, b), a), "batch"), TypeNotExist, b},
+		{"under fmt", fmt.Errorf("serve: %w", a), TypeNotExist, a},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := FindType(tt.err, tt.t)
+			if got != tt.want || ok != (tt.want != nil) {
+				t.Errorf("FindType() = %v, %v; want %v", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindTyped(t *testing.T) {
+	a := New(TypeNotExist, "a")
+	b := New(TypeTimeout, "b")
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"nil", nil, nil},
+		{"no match", fmt.Errorf("read: %w", io.EOF), nil},
+		{"no match in a join", stderrors.Join(io.EOF, io.ErrUnexpectedEOF), nil},
+		{"join in order", stderrors.Join(io.EOF, a, b), a},
+		{"join depth first", stderrors.Join(fmt.Errorf("x: %w", b), a), b},
+		{"nested join", stderrors.Join(stderrors.Join(io.EOF, b), a), b},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := FindTyped(tt.err)
+			if ok != (tt.want != nil) || (tt.want == nil && got != nil) || (tt.want != nil && got.(error) != tt.want) {
+				t.Errorf("FindTyped() = %v, %v; want %v", got, ok, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+// Package terrorstest checks where the stacks of errors were captured, for
+// regression tests of helpers that create or wrap errors.
+package terrorstest
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/thamaji/terrors"
+)
+
+// AssertOrigin fails t unless the first frame of the innermost stack of
+// err's chain is a function whose name ends with funcSuffix, such as
+// "store.(*DB).Load". Stacks captured by github.com/pkg/errors count.
+func AssertOrigin(t testing.TB, err error, funcSuffix string) {
+	t.Helper()
+
+	stacks := terrors.AllStacks(err)
+	if len(stacks) == 0 || len(stacks[len(stacks)-1]) == 0 {
+		t.Errorf("terrorstest: error %q has no stack, want origin %s", errorString(err), funcSuffix)
+		return
+	}
+
+	st := stacks[len(stacks)-1]
+	if !strings.HasSuffix(function(st[0]), funcSuffix) {
+		t.Errorf("terrorstest: origin of %q is %s, want %s; stack:%s", errorString(err), function(st[0]), funcSuffix, lines(st))
+	}
+}
+
+// AssertStackContains fails t unless a frame of any stack of err's chain is
+// a function whose name ends with funcSuffix.
+func AssertStackContains(t testing.TB, err error, funcSuffix string) {
+	t.Helper()
+
+	stacks := terrors.AllStacks(err)
+	var all strings.Builder
+	for _, st := range stacks {
+		for _, f := range st {
+			if strings.HasSuffix(function(f), funcSuffix) {
+				return
+			}
+		}
+		all.WriteString(lines(st))
+	}
+	if len(stacks) == 0 {
+		t.Errorf("terrorstest: error %q has no stack, want a frame of %s", errorString(err), funcSuffix)
+		return
+	}
+	t.Errorf("terrorstest: no frame of %s in the stacks of %q:%s", funcSuffix, errorString(err), all.String())
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return "<nil>"
+	}
+	return err.Error()
+}
+
+func function(f errors.Frame) string {
+	name, _, _ := frame(f)
+	return name
+}
+
+func lines(st errors.StackTrace) string {
+	var b strings.Builder
+	for _, f := range st {
+		name, file, line := frame(f)
+		fmt.Fprintf(&b, "\n\t%s %s:%d", name, file, line)
+	}
+	return b.String()
+}
+
+func frame(f errors.Frame) (function string, file string, line int) {
+	pc := uintptr(f) - 1
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return "unknown", "unknown", 0
+	}
+	file, line = fn.FileLine(pc)
+	return fn.Name(), file, line
+}
@@ -0,0 +1,128 @@
+package terrorstest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/thamaji/terrors"
+)
+
+// fakeTB records the failures of the assertions instead of failing the test.
+type fakeTB struct {
+	testing.TB
+	failures []string
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...interface{}) {
+	f.failures = append(f.failures, fmt.Sprintf(format, args...))
+}
+
+func loadUser() error {
+	return terrors.New(terrors.TypeNotExist, "user not found")
+}
+
+func handler() error {
+	return terrors.Wrap(terrors.TypeInternal, loadUser(), "handle")
+}
+
+func readFile() error {
+	return errors.New("permission denied")
+}
+
+func TestAssertOrigin(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		suffix string
+		fail   string
+	}{
+		{"pass", handler(), "terrorstest.loadUser", ""},
+		{"wrong origin", handler(), "terrorstest.handler", "origin of \"handle: user not found\" is github.com/thamaji/terrors/terrorstest.loadUser, want terrorstest.handler"},
+		{"no stack", fmt.Errorf("plain"), "terrorstest.loadUser", "error \"plain\" has no stack"},
+		{"nil", nil, "terrorstest.loadUser", "error \"<nil>\" has no stack"},
+		{"pkg/errors stack", terrors.Wrap(terrors.TypePermission, readFile(), "open"), "terrorstest.readFile", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeTB{}
+			AssertOrigin(fake, tt.err, tt.suffix)
+
+			switch {
+			case tt.fail == "" && len(fake.failures) != 0:
+				t.Errorf("AssertOrigin() failed: %q", fake.failures)
+			case tt.fail != "" && (len(fake.failures) != 1 || !strings.Contains(fake.failures[0], tt.fail)):
+				t.Errorf("AssertOrigin() failures = %q, want %q", fake.failures, tt.fail)
+			}
+		})
+	}
+}
+
+func TestAssertOriginListsFrames(t *testing.T) {
+	fake := &fakeTB{}
+	AssertOrigin(fake, handler(), "store.Load")
+	if len(fake.failures) != 1 {
+		t.Fatalf("failures = %q", fake.failures)
+	}
+
+	msg := fake.failures[0]
+	for _, fn := range []string{"terrorstest.loadUser", "terrorstest.handler", "terrorstest.TestAssertOriginListsFrames"} {
+		if !strings.Contains(msg, "\n\tgithub.com/thamaji/terrors/"+fn+" ") {
+			t.Errorf("failure does not list %s:\n%s", fn, msg)
+		}
+	}
+	if !strings.Contains(msg, "terrorstest_test.go:") {
+		t.Errorf("failure does not list file and line:\n%s", msg)
+	}
+}
+
+func TestAssertStackContains(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		suffix string
+		fail   string
+	}{
+		{"origin", handler(), "terrorstest.loadUser", ""},
+		{"outer frame", handler(), "terrorstest.TestAssertStackContains", ""},
+		{"wrap site", handler(), "terrorstest.handler", ""},
+		{"pkg/errors stack", fmt.Errorf("open: %w", readFile()), "terrorstest.readFile", ""},
+		{"missing", handler(), "store.Load", "no frame of store.Load in the stacks of \"handle: user not found\""},
+		{"no stack", fmt.Errorf("plain"), "terrorstest.loadUser", "error \"plain\" has no stack"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeTB{}
+			AssertStackContains(fake, tt.err, tt.suffix)
+
+			switch {
+			case tt.fail == "" && len(fake.failures) != 0:
+				t.Errorf("AssertStackContains() failed: %q", fake.failures)
+			case tt.fail != "" && (len(fake.failures) != 1 || !strings.Contains(fake.failures[0], tt.fail)):
+				t.Errorf("AssertStackContains() failures = %q, want %q", fake.failures, tt.fail)
+			}
+		})
+	}
+}
+
+func TestAssertStackContainsListsFrames(t *testing.T) {
+	fake := &fakeTB{}
+	AssertStackContains(fake, handler(), "store.Load")
+	if len(fake.failures) != 1 {
+		t.Fatalf("failures = %q", fake.failures)
+	}
+
+	// the frames of both stacks of the chain are listed
+	msg := fake.failures[0]
+	if n := strings.Count(msg, "terrorstest.TestAssertStackContainsListsFrames "); n != 2 {
+		t.Errorf("failure lists the test frame %d times, want 2:\n%s", n, msg)
+	}
+	if !strings.Contains(msg, "terrorstest.loadUser ") || !strings.Contains(msg, "terrorstest.handler ") {
+		t.Errorf("failure does not list the origin and wrap frames:\n%s", msg)
+	}
+}
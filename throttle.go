@@ -0,0 +1,93 @@
+package terrors
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// throttleSize bounds the number of fingerprints remembered by Once and
+// Every. Each entry costs a fingerprint and a time, well under 100 bytes.
+const throttleSize = 4096
+
+// throttleEntry is a remembered fingerprint and the time until which it is
+// not reported again.
+type throttleEntry struct {
+	key   string
+	until time.Time
+}
+
+var (
+	throttleMu  sync.Mutex
+	throttleNow = time.Now
+
+	// throttleLRU holds the entries, the most recently seen first, and
+	// throttleKeys its elements by fingerprint, so that finding, refreshing
+	// and evicting an entry take constant time.
+	throttleLRU  = list.New()
+	throttleKeys = map[string]*list.Element{}
+)
+
+// Once reports whether an error with the fingerprint of err is seen for the
+// first time, see Fingerprint. At most 4096 fingerprints are remembered; when
+// that many are in use the least recently seen one is forgotten and may be
+// reported again.
+func Once(err error) bool {
+	return throttle(err, func(time.Time) time.Time { return maxTime })
+}
+
+// Every reports whether an error with the fingerprint of err was not
+// reported in the last d, so that
+//
+//	if terrors.Every(err, time.Minute) { log.Error(err) }
+//
+// logs each kind of error at most once a minute. It shares the bound of Once.
+func Every(err error, d time.Duration) bool {
+	return throttle(err, func(now time.Time) time.Time { return now.Add(d) })
+}
+
+// SetThrottleClock replaces the clock used by Once and Every and forgets all
+// fingerprints. A nil clock restores time.Now.
+func SetThrottleClock(now func() time.Time) {
+	if now == nil {
+		now = time.Now
+	}
+
+	throttleMu.Lock()
+	throttleNow = now
+	throttleLRU.Init()
+	throttleKeys = map[string]*list.Element{}
+	throttleMu.Unlock()
+}
+
+var maxTime = time.Unix(1<<62, 0)
+
+func throttle(err error, until func(time.Time) time.Time) bool {
+	if err == nil {
+		return false
+	}
+
+	key := Fingerprint(err)
+
+	throttleMu.Lock()
+	defer throttleMu.Unlock()
+
+	now := throttleNow()
+	if el, ok := throttleKeys[key]; ok {
+		throttleLRU.MoveToFront(el)
+		e := el.Value.(*throttleEntry)
+		if now.Before(e.until) {
+			return false
+		}
+		e.until = until(now)
+		return true
+	}
+
+	if len(throttleKeys) >= throttleSize {
+		oldest := throttleLRU.Back()
+		delete(throttleKeys, throttleLRU.Remove(oldest).(*throttleEntry).key)
+	}
+	throttleKeys[key] = throttleLRU.PushFront(&throttleEntry{key: key, until: until(now)})
+
+	return true
+}
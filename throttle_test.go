@@ -0,0 +1,132 @@
+package terrors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// fakeClock sets the clock of Once and Every to the returned time, which the
+// test advances; the real clock is restored on cleanup.
+func fakeClock(t *testing.T) *time.Time {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	SetThrottleClock(func() time.Time { return now })
+	t.Cleanup(func() { SetThrottleClock(nil) })
+	return &now
+}
+
+func throttled(i int) error {
+	return stderrors.New(fmt.Sprintf("error %d", i))
+}
+
+func TestOnce(t *testing.T) {
+	now := fakeClock(t)
+
+	if Once(nil) {
+		t.Error("Once(nil) = true")
+	}
+	if !Once(throttled(1)) {
+		t.Error("first Once = false")
+	}
+	*now = now.Add(24 * time.Hour)
+	if Once(throttled(1)) {
+		t.Error("Once of the same fingerprint = true")
+	}
+	if !Once(throttled(2)) {
+		t.Error("Once of another fingerprint = false")
+	}
+}
+
+func TestEvery(t *testing.T) {
+	now := fakeClock(t)
+
+	tests := []struct {
+		after time.Duration
+		want  bool
+	}{
+		{0, true},
+		{time.Second, false},
+		{time.Minute - 1, false},
+		{time.Minute, true},
+		{time.Minute + time.Second, false},
+		{3 * time.Minute, true},
+	}
+	start := *now
+	for _, tt := range tests {
+		*now = start.Add(tt.after)
+		if got := Every(throttled(1), time.Minute); got != tt.want {
+			t.Errorf("Every() after %v = %v, want %v", tt.after, got, tt.want)
+		}
+	}
+}
+
+func TestThrottleEviction(t *testing.T) {
+	fakeClock(t)
+
+	for i := 0; i < throttleSize; i++ {
+		Every(throttled(i), time.Hour)
+	}
+	if Every(throttled(0), time.Hour) {
+		t.Fatal("a remembered fingerprint was reported")
+	}
+
+	// 0 was just seen, so 1 is the least recently seen
+	if !Every(throttled(throttleSize), time.Hour) {
+		t.Fatal("a new fingerprint at the bound was not reported")
+	}
+	if n := len(throttleKeys); n != throttleSize || throttleLRU.Len() != throttleSize {
+		t.Errorf("%d/%d fingerprints remembered, want %d", n, throttleLRU.Len(), throttleSize)
+	}
+	if Every(throttled(0), time.Hour) {
+		t.Error("the fingerprint seen last was evicted")
+	}
+	if !Every(throttled(1), time.Hour) {
+		t.Error("the least recently seen fingerprint was not evicted")
+	}
+	if Every(throttled(throttleSize-1), time.Hour) {
+		t.Error("a later fingerprint was evicted")
+	}
+}
+
+func TestThrottleEvictionOnce(t *testing.T) {
+	fakeClock(t)
+
+	// entries of Once never expire, they are still evicted in order
+	for i := 0; i < throttleSize; i++ {
+		Once(throttled(i))
+	}
+	for i := throttleSize; i < throttleSize+10; i++ {
+		if !Once(throttled(i)) {
+			t.Fatalf("Once() of new fingerprint %d at the bound = false", i)
+		}
+	}
+	if len(throttleKeys) != throttleSize {
+		t.Errorf("%d fingerprints remembered, want %d", len(throttleKeys), throttleSize)
+	}
+	// 0 was evicted first, and coming back evicts 10
+	if !Once(throttled(0)) || Once(throttled(11)) {
+		t.Error("evicted fingerprints other than the least recently seen")
+	}
+}
+
+// BenchmarkOnceFull measures Once with the bound reached by fingerprints
+// that never expire, where every new fingerprint evicts one.
+func BenchmarkOnceFull(b *testing.B) {
+	SetThrottleClock(nil)
+	defer SetThrottleClock(nil)
+
+	for i := 0; i < throttleSize; i++ {
+		Once(throttled(i))
+	}
+	errs := make([]error, 1024)
+	for i := range errs {
+		errs[i] = throttled(throttleSize + i)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Once(errs[i%len(errs)])
+	}
+}
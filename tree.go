@@ -0,0 +1,141 @@
+package terrors
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Tree renders err as an indented tree: one node per run of wrapped errors,
+// labelled with its type and messages, and the errors of a join
+// (Unwrap() []error) as its children. Stacks are left out; an error found
+// again below itself is printed as "(cycle)".
+func Tree(err error) string {
+	var b strings.Builder
+	FprintTree(&b, err)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func FprintTree(w io.Writer, err error) {
+	if err == nil {
+		return
+	}
+	printTree(w, err, "", "", map[interface{}]bool{})
+}
+
+func printTree(w io.Writer, err error, prefix string, childPrefix string, path map[interface{}]bool) {
+	label, children, cycle := treeNode(err, path)
+	if cycle {
+		label += " (cycle)"
+	}
+	fmt.Fprintf(w, "%s%s\n", prefix, label)
+
+	for i, child := range children {
+		if key, ok := treeKey(child); ok && path[key] {
+			connector := "├─ "
+			if i == len(children)-1 {
+				connector = "└─ "
+			}
+			fmt.Fprintf(w, "%s%s(cycle)\n", childPrefix, connector)
+			continue
+		}
+		if i == len(children)-1 {
+			printTree(w, child, childPrefix+"└─ ", childPrefix+"   ", path)
+		} else {
+			printTree(w, child, childPrefix+"├─ ", childPrefix+"│  ", path)
+		}
+	}
+
+	for e := err; e != nil; e = unwrapOnce(e) {
+		key, ok := treeKey(e)
+		if !ok || !path[key] {
+			break
+		}
+		delete(path, key)
+	}
+}
+
+// treeNode follows err's chain down to a join or the end of the chain,
+// marking the errors it goes through in path. It returns the node label
+// and the errors of the join, if any.
+func treeNode(err error, path map[interface{}]bool) (label string, children []error, cycle bool) {
+	var msgs []string
+	t, typed := TypeUnknown, false
+	complete := false
+
+	for e := err; e != nil; e = unwrapOnce(e) {
+		if key, ok := treeKey(e); ok {
+			if path[key] {
+				cycle = true
+				break
+			}
+			path[key] = true
+		}
+
+		if te, ok := e.(TypedError); ok && !typed {
+			t, typed = te.Type(), true
+		}
+		if joined, ok := e.(interface{ Unwrap() []error }); ok {
+			for _, child := range joined.Unwrap() {
+				if child != nil {
+					children = append(children, child)
+				}
+			}
+			break
+		}
+		if !complete {
+			msg, split := ownMessage(e)
+			if msg != "" {
+				msgs = append(msgs, msg)
+			}
+			complete = !split
+		}
+	}
+
+	if !typed {
+		t = DefaultType()
+	}
+	if len(msgs) == 0 {
+		return fmt.Sprintf("%s: (%d errors)", t, len(children)), children, cycle
+	}
+	return fmt.Sprintf("%s: %s", t, strings.Join(msgs, ": ")), children, cycle
+}
+
+// treeKey identifies err for cycle detection. Only pointers can form cycles.
+func treeKey(err error) (interface{}, bool) {
+	if err == nil || reflect.TypeOf(err).Kind() != reflect.Ptr {
+		return nil, false
+	}
+	return err, true
+}
+
+// ErrorsOf returns every error of err's chain and of the joins within it,
+// depth first in the order walked by HasType and FindType: a layer before
+// the errors it wraps, and the errors of a join in order. An error reached
+// twice is listed once, so cycles end. A nil error yields nil.
+func ErrorsOf(err error) []error {
+	var errs []error
+	seen := map[interface{}]bool{}
+	var visit func(err error)
+	visit = func(err error) {
+		for ; err != nil; err = unwrapOnce(err) {
+			if key, ok := treeKey(err); ok {
+				if seen[key] {
+					return
+				}
+				seen[key] = true
+			}
+			errs = append(errs, err)
+
+			if joined, ok := err.(interface{ Unwrap() []error }); ok {
+				for _, e := range joined.Unwrap() {
+					visit(e)
+				}
+				return
+			}
+		}
+	}
+	visit(err)
+	return errs
+}
@@ -0,0 +1,122 @@
+package terrors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+// multiError is a join that, unlike errors.Join, keeps nil errors.
+type multiError []error
+
+func (m multiError) Error() string {
+	return fmt.Sprintf("%d errors", len(m))
+}
+
+func (m multiError) Unwrap() []error {
+	return m
+}
+
+func joinOfJoins() error {
+	inner := stderrors.Join(
+		New(TypeNotExist, "user 1 not found"),
+		Wrap(TypeTimeout, fmt.Errorf("dial: %w", io.EOF), "fetch user 2"),
+	)
+	return Wrap(TypeInternal, stderrors.Join(
+		WithMessage(TypeInvalid, inner, "validate batch"),
+		multiError{nil, New(TypeConflict, "row locked"), nil},
+		io.ErrUnexpectedEOF,
+	), "import")
+}
+
+func TestTree(t *testing.T) {
+	golden(t, "tree_join_of_joins", Tree(joinOfJoins()))
+}
+
+func TestTreeSimple(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nil", nil, ""},
+		{"foreign", io.EOF, "unknown: EOF"},
+		{"chain", Wrap(TypeInternal, fmt.Errorf("read: %w", New(TypeNotExist, "missing")), "load"), "internal: load: read: missing"},
+		{"only nil children", multiError{nil, nil}, "unknown: (0 errors)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Tree(tt.err); got != tt.want {
+				t.Errorf("Tree() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTreeCycle(t *testing.T) {
+	p := &pointerJoin{}
+	p.errs = []error{io.EOF, nil, Wrap(TypeInternal, p, "again")}
+
+	golden(t, "tree_cycle", Tree(p))
+}
+
+type pointerJoin struct {
+	errs []error
+}
+
+func (p *pointerJoin) Error() string {
+	return "pointer join"
+}
+
+func (p *pointerJoin) Unwrap() []error {
+	return p.errs
+}
+
+// described lists the errors of ErrorsOf(err) by Go type and message.
+func described(err error) []string {
+	var s []string
+	for _, e := range ErrorsOf(err) {
+		s = append(s, fmt.Sprintf("%T %s", e, e))
+	}
+	return s
+}
+
+func TestErrorsOf(t *testing.T) {
+	got := described(joinOfJoins())
+	want := []string{
+		"*terrors.wrapped import: validate batch: user 1 not found\nfetch user 2: dial: EOF\n3 errors\nunexpected EOF",
+		"*errors.joinError validate batch: user 1 not found\nfetch user 2: dial: EOF\n3 errors\nunexpected EOF",
+		"*terrors.withMessage validate batch: user 1 not found\nfetch user 2: dial: EOF",
+		"*errors.joinError user 1 not found\nfetch user 2: dial: EOF",
+		"*terrors.fundamental user 1 not found",
+		"*terrors.wrapped fetch user 2: dial: EOF",
+		"*fmt.wrapError dial: EOF",
+		"*errors.errorString EOF",
+		"terrors.multiError 3 errors",
+		"*terrors.fundamental row locked",
+		"*errors.errorString unexpected EOF",
+	}
+	if fmt.Sprintf("%q", got) != fmt.Sprintf("%q", want) {
+		t.Errorf("ErrorsOf() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestErrorsOfShared(t *testing.T) {
+	shared := New(TypeNotExist, "missing")
+	err := stderrors.Join(Wrap(TypeInternal, shared, "a"), shared, WithOp(shared, "b"))
+	if got := len(ErrorsOf(err)); got != 4 {
+		t.Errorf("ErrorsOf() has %d errors, want 4: %q", got, described(err))
+	}
+
+	p := &pointerJoin{}
+	p.errs = []error{io.EOF, Wrap(TypeInternal, p, "again")}
+	if got := described(p); len(got) != 3 {
+		t.Errorf("ErrorsOf() of a cycle = %q", got)
+	}
+
+	if ErrorsOf(nil) != nil {
+		t.Error("ErrorsOf(nil) != nil")
+	}
+}
@@ -0,0 +1,149 @@
+package terrors
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	windowBuckets = 60
+	// windowFingerprints bounds the fingerprints counted per bucket; the
+	// errors of further fingerprints are only counted by type.
+	windowFingerprints = 1024
+)
+
+// FingerprintCount is the number of errors with a fingerprint seen by a
+// Window.
+type FingerprintCount struct {
+	Fingerprint string
+	Type        Type
+	Count       int
+}
+
+type WindowOption func(*Window)
+
+// WindowClock replaces the clock of a Window, which defaults to time.Now.
+func WindowClock(now func() time.Time) WindowOption {
+	return func(w *Window) {
+		w.now = now
+	}
+}
+
+// Window counts the errors observed over a sliding period, by type and by
+// fingerprint, for burst detection:
+//
+//	if w.Count(terrors.TypeUnavailable) > 100 { alert() }
+//
+// The period is split in 60 buckets, so counts move in steps of a sixtieth
+// of the period. Memory is bounded by 60 buckets of at most 1024
+// fingerprints each. A Window is safe for concurrent use.
+type Window struct {
+	mu      sync.Mutex
+	width   time.Duration
+	now     func() time.Time
+	buckets [windowBuckets]windowBucket
+}
+
+type windowBucket struct {
+	n            int64
+	types        map[Type]int
+	fingerprints map[string]*FingerprintCount
+}
+
+func NewWindow(period time.Duration, opts ...WindowOption) *Window {
+	w := &Window{width: period / windowBuckets, now: time.Now}
+	if w.width <= 0 {
+		w.width = 1
+	}
+	for i := range w.buckets {
+		w.buckets[i].n = -1
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Observe counts err, unless it is nil.
+func (w *Window) Observe(err error) {
+	if err == nil {
+		return
+	}
+	t, fp := TypeOf(err), Fingerprint(err)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n := w.now().UnixNano() / int64(w.width)
+	i := n % windowBuckets
+	if i < 0 {
+		i += windowBuckets
+	}
+	b := &w.buckets[i]
+	if b.n != n {
+		*b = windowBucket{n: n, types: map[Type]int{}, fingerprints: map[string]*FingerprintCount{}}
+	}
+
+	b.types[t]++
+	if c, ok := b.fingerprints[fp]; ok {
+		c.Count++
+	} else if len(b.fingerprints) < windowFingerprints {
+		b.fingerprints[fp] = &FingerprintCount{Fingerprint: fp, Type: t, Count: 1}
+	}
+}
+
+// Count returns the number of errors of type t observed during the period.
+func (w *Window) Count(t Type) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	count := 0
+	w.each(func(b *windowBucket) {
+		count += b.types[t]
+	})
+	return count
+}
+
+// TopFingerprints returns the n fingerprints observed the most during the
+// period, most frequent first and by fingerprint for equal counts.
+func (w *Window) TopFingerprints(n int) []FingerprintCount {
+	w.mu.Lock()
+	counts := map[string]*FingerprintCount{}
+	w.each(func(b *windowBucket) {
+		for fp, c := range b.fingerprints {
+			if total, ok := counts[fp]; ok {
+				total.Count += c.Count
+			} else {
+				copied := *c
+				counts[fp] = &copied
+			}
+		}
+	})
+	w.mu.Unlock()
+
+	top := make([]FingerprintCount, 0, len(counts))
+	for _, c := range counts {
+		top = append(top, *c)
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].Fingerprint < top[j].Fingerprint
+	})
+	if n >= 0 && len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
+
+// each calls fn with the buckets within the period. w.mu must be held.
+func (w *Window) each(fn func(b *windowBucket)) {
+	n := w.now().UnixNano() / int64(w.width)
+	for i := range w.buckets {
+		if b := &w.buckets[i]; b.n > n-windowBuckets && b.n <= n {
+			fn(b)
+		}
+	}
+}
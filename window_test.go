@@ -0,0 +1,133 @@
+package terrors
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// windowClock returns a Window over a minute, in buckets of a second, whose
+// clock is the returned time.
+func windowClock() (*Window, *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return NewWindow(time.Minute, WindowClock(func() time.Time { return now })), &now
+}
+
+func TestWindowExpiry(t *testing.T) {
+	w, now := windowClock()
+	start := *now
+
+	w.Observe(New(TypeUnavailable, "down"))
+	w.Observe(nil)
+	*now = start.Add(30 * time.Second)
+	w.Observe(New(TypeUnavailable, "down"))
+	w.Observe(New(TypeTimeout, "slow"))
+
+	tests := []struct {
+		after       time.Duration
+		unavailable int
+		timeout     int
+	}{
+		{30 * time.Second, 2, 1},
+		{59*time.Second + 999*time.Millisecond, 2, 1},
+		{time.Minute, 1, 1},
+		{89 * time.Second, 1, 1},
+		{90 * time.Second, 0, 0},
+		{time.Hour, 0, 0},
+	}
+	for _, tt := range tests {
+		*now = start.Add(tt.after)
+		if got := w.Count(TypeUnavailable); got != tt.unavailable {
+			t.Errorf("after %v: Count(unavailable) = %d, want %d", tt.after, got, tt.unavailable)
+		}
+		if got := w.Count(TypeTimeout); got != tt.timeout {
+			t.Errorf("after %v: Count(timeout) = %d, want %d", tt.after, got, tt.timeout)
+		}
+	}
+}
+
+func TestWindowBucketReuse(t *testing.T) {
+	w, now := windowClock()
+
+	w.Observe(throttled(1))
+	w.Observe(throttled(1))
+	*now = now.Add(time.Minute)
+	// same bucket index, a new period: the old counts are dropped
+	w.Observe(throttled(1))
+
+	if got := w.Count(DefaultType()); got != 1 {
+		t.Errorf("Count() = %d, want 1", got)
+	}
+	if top := w.TopFingerprints(-1); len(top) != 1 || top[0].Count != 1 {
+		t.Errorf("TopFingerprints() = %+v", top)
+	}
+}
+
+func TestWindowTopFingerprints(t *testing.T) {
+	w, now := windowClock()
+
+	for i := 0; i < 3; i++ {
+		for j := 0; j <= i; j++ {
+			w.Observe(throttled(i))
+			*now = now.Add(time.Second)
+		}
+	}
+	w.Observe(throttled(3))
+
+	top := w.TopFingerprints(2)
+	if len(top) != 2 || top[0].Fingerprint != Fingerprint(throttled(2)) || top[0].Count != 3 || top[1].Count != 2 {
+		t.Errorf("TopFingerprints(2) = %+v", top)
+	}
+	if top[0].Type != DefaultType() {
+		t.Errorf("Type = %v", top[0].Type)
+	}
+	if all := w.TopFingerprints(-1); len(all) != 4 {
+		t.Errorf("TopFingerprints(-1) has %d entries, want 4", len(all))
+	}
+}
+
+func TestWindowFingerprintBound(t *testing.T) {
+	w, _ := windowClock()
+
+	for i := 0; i < windowFingerprints+10; i++ {
+		w.Observe(throttled(i))
+	}
+	if got := w.Count(DefaultType()); got != windowFingerprints+10 {
+		t.Errorf("Count() = %d, want every error", got)
+	}
+	if got := len(w.TopFingerprints(-1)); got != windowFingerprints {
+		t.Errorf("%d fingerprints kept, want %d", got, windowFingerprints)
+	}
+}
+
+func TestWindowConcurrent(t *testing.T) {
+	w := NewWindow(time.Hour)
+	const goroutines, observations = 8, 500
+
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < observations; j++ {
+				w.Observe(throttled(j % 10))
+				if j%100 == 0 {
+					_ = w.Count(DefaultType())
+					_ = w.TopFingerprints(3)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := w.Count(DefaultType()); got != goroutines*observations {
+		t.Errorf("Count() = %d, want %d", got, goroutines*observations)
+	}
+	total := 0
+	for _, c := range w.TopFingerprints(-1) {
+		total += c.Count
+	}
+	if total != goroutines*observations {
+		t.Errorf("fingerprint counts sum to %d, want %d", total, goroutines*observations)
+	}
+}